@@ -1,18 +1,284 @@
 package gospec
 
+import (
+	"go/constant"
+	"go/types"
+)
+
+// Assignment reports whether v is assignable to t.
+// v may be a name, or an expression such as "1 << 10"; t may be a name or a type expression.
 func (s *Spec) Assignment(v, t string) bool {
-	V := s.MustGetValidType(v)
+	x := s.mustGetOperand(v)
 	T := s.MustGetValidType(t)
+	return mustAnswer(s.oracle.Assignable(s, Operand{x.typ, x.val}, T))
+}
+
+func Assignment(code, v, t string) bool {
+	s := cachedSpec(code)
+	return s.Assignment(v, t)
+}
+
+// AssignmentE is like Assignment, but returns an error instead of panicking,
+// such as a *NotFoundError if v or t is not found.
+func (s *Spec) AssignmentE(v, t string) (bool, error) {
+	x, err := s.getOperand(v)
+	if err != nil {
+		return false, err
+	}
+	T, err := s.getType(t)
+	if err != nil {
+		return false, err
+	}
+	return s.oracle.Assignable(s, Operand{x.typ, x.val}, T)
+}
+
+// AssignmentE is like Assignment, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.AssignmentE.
+func AssignmentE(code, v, t string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.AssignmentE(v, t)
+}
+
+// AssignmentTypes reports whether a non-constant value of type v is assignable to a variable of type t.
+func AssignmentTypes(v, t types.Type) bool {
+	return assignable(v, nil, t, nil)
+}
 
-	x := &operand{mode: value, typ: V}
-	_assignment(s.checker, x, T, "")
-	if x.mode > 0 {
+// assignable reports whether a value of type V is assignable to a variable of type T,
+// val is the value of a constant, or nil. sizes is used to tell the range of int, uint and uintptr,
+// the sizes of gc on amd64 are used if it is nil.
+//
+// It follows go/types.(*Checker).assignment: an untyped value is converted to T first,
+// or to its default type if T is an interface.
+func assignable(V types.Type, val constant.Value, T types.Type, sizes types.Sizes) bool {
+	if IsUntyped(V) {
+		target := T
+		if isNonTypeParamInterface(T) {
+			target = types.Default(V)
+		}
+		newType, newVal, ok := implicitTypeAndValue(V, val, target, sizes)
+		if !ok {
+			return false
+		}
+		V = newType
+		if newVal != nil {
+			val = newVal
+		}
+	}
+	return assignableTo(V, val, T, sizes)
+}
+
+// assignableTo reports whether a value of type V is assignable to T by the rules of the spec:
+//
+//	V and T are identical.
+//	V and T have identical underlying types, and at least one of V or T is not a named type.
+//	T is an interface type, and V implements T.
+//	V is a bidirectional channel type, T is a channel type, V and T have identical element types,
+//	and at least one of V or T is not a named type.
+//	V is untyped nil, and T is a pointer, function, slice, map, channel or interface type.
+//	V is an untyped constant representable by a value of type T.
+//
+// and the rules for type parameters: they are applied to each specific type in the type set of them.
+func assignableTo(V types.Type, val constant.Value, T types.Type, sizes types.Sizes) bool {
+	if !isValid(V) || !isValid(T) {
+		return true // as go/types, avoid spurious errors
+	}
+	V = types.Unalias(V)
+	T = types.Unalias(T)
+
+	if types.Identical(V, T) {
 		return true
 	}
+
+	Vu := V.Underlying()
+	Tu := T.Underlying()
+	Vp, _ := V.(*types.TypeParam)
+	Tp, _ := T.(*types.TypeParam)
+
+	if IsUntyped(Vu) {
+		if Tp != nil {
+			return typeSetIs(Tp, func(t *types.Term) bool {
+				if t == nil {
+					return false
+				}
+				_, _, ok := implicitTypeAndValue(V, val, t.Type(), sizes)
+				return ok
+			})
+		}
+		_, _, ok := implicitTypeAndValue(V, val, T, sizes)
+		return ok
+	}
+
+	if types.Identical(Vu, Tu) && (!hasName(V) || !hasName(T)) && Vp == nil && Tp == nil {
+		return true
+	}
+
+	if Ti, ok := Tu.(*types.Interface); ok && Tp == nil {
+		if types.Implements(V, Ti) {
+			return true
+		}
+		// a type parameter may still be assignable to T
+		if Vp == nil {
+			return false
+		}
+	}
+
+	if Vc, ok := Vu.(*types.Chan); ok && Vc.Dir() == types.SendRecv {
+		if Tc, ok := Tu.(*types.Chan); ok && types.Identical(Vc.Elem(), Tc.Elem()) {
+			return !hasName(V) || !hasName(T)
+		}
+	}
+
+	if Vp == nil && Tp == nil {
+		return false
+	}
+
+	// V is not a named type and T is a type parameter, V is assignable to each specific type of T
+	if !hasName(V) && Tp != nil {
+		return typeSetIs(Tp, func(t *types.Term) bool {
+			return t != nil && assignableTo(V, val, t.Type(), sizes)
+		})
+	}
+
+	// V is a type parameter and T is not a named type, each specific type of V is assignable to T
+	if Vp != nil && !hasName(T) {
+		return typeSetIs(Vp, func(v *types.Term) bool {
+			return v != nil && assignableTo(v.Type(), val, T, sizes)
+		})
+	}
+
 	return false
 }
 
-func Assignment(code, v, t string) bool {
-	s := NewSpec(code)
-	return s.Assignment(v, t)
+// implicitTypeAndValue returns the type and value an untyped value of type V becomes when it is used as target,
+// ok is false if it can not be, see go/types.(*Checker).implicitTypeAndValue.
+func implicitTypeAndValue(V types.Type, val constant.Value, target types.Type, sizes types.Sizes) (types.Type, constant.Value, bool) {
+	if IsTyped(V) || !isValid(target) {
+		return V, nil, true
+	}
+
+	if IsUntyped(target) {
+		if m := maxType(V, target); m != nil {
+			return m, nil, true
+		}
+		return nil, nil, false
+	}
+
+	isNil := V == types.Typ[types.UntypedNil]
+	switch u := target.Underlying().(type) {
+	case *types.Basic:
+		if val != nil {
+			rounded := val
+			if !representableConst(val, u, sizes, &rounded) {
+				return nil, nil, false
+			}
+			return target, rounded, true
+		}
+		// non-constant untyped values are the results of comparisons, the operands of shifts, and nil
+		switch V.(*types.Basic).Kind() {
+		case types.UntypedBool:
+			if !IsBoolean(target) {
+				return nil, nil, false
+			}
+		case types.UntypedInt, types.UntypedRune:
+			// the operand of a shift, such as 1 of 1 << s, has the type of target, which must be an integer type
+			if !IsInteger(target) {
+				return nil, nil, false
+			}
+		case types.UntypedFloat, types.UntypedComplex:
+			if !IsNumeric(target) {
+				return nil, nil, false
+			}
+		case types.UntypedString:
+			if !IsString(target) {
+				return nil, nil, false
+			}
+		case types.UntypedNil:
+			if !hasNil(target) {
+				return nil, nil, false
+			}
+			return V, nil, true
+		default:
+			return nil, nil, false
+		}
+	case *types.Interface:
+		if isTypeParam(target) {
+			if !underIs(target, func(u types.Type) bool {
+				if u == nil {
+					return false
+				}
+				_, _, ok := implicitTypeAndValue(V, val, u, sizes)
+				return ok
+			}) {
+				return nil, nil, false
+			}
+			if isNil {
+				return V, nil, true
+			}
+			break
+		}
+		// nil is kept untyped
+		if isNil {
+			return V, nil, true
+		}
+		// untyped values can not be assigned to non-empty interfaces
+		if !u.Empty() {
+			return nil, nil, false
+		}
+		return types.Default(V), nil, true
+	case *types.Pointer, *types.Signature, *types.Slice, *types.Map, *types.Chan:
+		if !isNil {
+			return nil, nil, false
+		}
+		return V, nil, true
+	default:
+		return nil, nil, false
+	}
+	return target, nil, true
+}
+
+// maxType returns the larger of the untyped types x and y, or nil if they are not both numeric.
+func maxType(x, y types.Type) types.Type {
+	if x == y {
+		return x
+	}
+	if IsUntyped(x) && IsNumeric(x) && IsUntyped(y) && IsNumeric(y) {
+		if x.(*types.Basic).Kind() > y.(*types.Basic).Kind() {
+			return x
+		}
+		return y
+	}
+	return nil
+}
+
+func isValid(t types.Type) bool {
+	return types.Unalias(t) != types.Typ[types.Invalid]
+}
+
+func isTypeParam(t types.Type) bool {
+	_, ok := types.Unalias(t).(*types.TypeParam)
+	return ok
+}
+
+func isNonTypeParamInterface(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Interface)
+	return ok && !isTypeParam(t)
+}
+
+// hasNil reports whether nil is a value of t.
+func hasNil(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer
+	case *types.Slice, *types.Pointer, *types.Signature, *types.Map, *types.Chan:
+		return true
+	case *types.Interface:
+		return !isTypeParam(t) || underIs(t, func(u types.Type) bool {
+			return u != nil && hasNil(u)
+		})
+	}
+	return false
 }
@@ -181,3 +181,128 @@ failed`)
 		}
 	}
 }
+
+// assignmentCorpus declares values and types, every value is checked against every type
+const assignmentCorpus = `
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+type (
+	MyInt    int
+	MyInt8   int8
+	IntAlias = int
+	MyString string
+	MyBool   bool
+	Ints     []int
+	IntChan  chan int
+	RecvChan <-chan int
+	Func     func(int) string
+	Point    struct{ X, Y int }
+	PointPtr *Point
+	Any      interface{}
+	Stringer interface{ String() string }
+	Number   interface{ ~int | ~float64 }
+)
+
+func (p Point) String() string { return fmt.Sprint(p.X, p.Y) }
+
+const (
+	c1      = 1
+	c300    = 300
+	cNeg    = -1
+	cFloat  = 1.5
+	cWhole  = 2.0
+	cBig    = 1 << 70
+	cRune   = 'a'
+	cStr    = "foo"
+	cBool   = true
+	cCmplx  = 1 + 0i
+	cCmplx2 = 1 + 2i
+	cTyped  MyInt = 1
+)
+
+var (
+	vInt      int
+	vMyInt    MyInt
+	vInt8     int8
+	vFloat32  float32
+	vString   string
+	vBytes    []byte
+	vInts     []int
+	vMyInts   Ints
+	vChan     chan int
+	vIntChan  IntChan
+	vRecvChan <-chan int
+	vFunc     func(int) string
+	vMyFunc   Func
+	vPoint    Point
+	vPointPtr *Point
+	vStruct   struct{ X, Y int }
+	vAny      interface{}
+	vStringer fmt.Stringer
+	vReader   io.Reader
+	vUnsafe   unsafe.Pointer
+	vCmp      = vInt == 1
+	vMap      map[string]int
+	vUintptr  uintptr
+	vArrPtr   *[2]int
+	vShift    uint
+	vTagged   struct {
+		X int ` + "`" + `json:"x"` + "`" + `
+		Y int
+	}
+)
+
+func Generic[T Number, P any, S ~[]int, F ~float32 | ~int8](t T, p P, s S, f F) {
+	var _ T = 1
+	var _ S = nil
+}
+`
+
+var assignmentValues = []string{
+	"nil", "c1", "c300", "cNeg", "cFloat", "cWhole", "cBig", "cRune", "cStr", "cBool", "cCmplx", "cCmplx2", "cTyped",
+	"vInt", "vMyInt", "vInt8", "vFloat32", "vString", "vBytes", "vInts", "vMyInts", "vChan", "vIntChan", "vRecvChan",
+	"vFunc", "vMyFunc", "vPoint", "vPointPtr", "vStruct", "vAny", "vStringer", "vReader", "vUnsafe", "vCmp", "vMap",
+	"vUintptr", "vArrPtr", "vTagged", "&vTagged", "&vPoint", "1 << 10", "1.0 << 3", "vInt == 1", "'a' + 1", `"a" + "b"`,
+	"1 << vShift", "1.0 << vShift", "'a' << vShift", "1.0 << 33",
+	"Generic.t", "Generic.p", "Generic.s", "Generic.f",
+}
+
+var assignmentTypes = []string{
+	"int", "int8", "uint", "uint8", "uintptr", "float32", "float64", "complex64", "complex128", "string", "bool", "rune",
+	"MyInt", "MyInt8", "IntAlias", "MyString", "MyBool", "Ints", "[]int", "IntChan", "RecvChan", "chan int", "<-chan int",
+	"chan<- int", "Func", "func(int) string", "Point", "PointPtr", "*Point", "struct{ X, Y int }", "Any", "Stringer",
+	"fmt.Stringer", "io.Reader", "interface{}", "unsafe.Pointer", "map[string]int", "[]byte", "[]rune", "[2]int", "*[2]int",
+	"[3]int", "Generic.T", "Generic.P", "Generic.S", "Generic.F",
+}
+
+// the rules engine must agree with go/types.(*Checker).assignment
+func TestAssignmentMatchesChecker(t *testing.T) {
+	s := NewSpec(assignmentCorpus, WithSearchKind(SearchAll))
+	for _, v := range assignmentValues {
+		for _, typ := range assignmentTypes {
+			want, err := linkAssignment(s.checker, s.mustGetOperand(v), s.MustGetValidType(typ))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Assignment(v, typ) != want {
+				t.Errorf("Assignment(%s, %s) should be %v", v, typ, want)
+			}
+		}
+	}
+}
+
+// func AssignmentTypes(v, t types.Type) bool
+func TestAssignmentTypes(t *testing.T) {
+	s := NewSpec(`type T int; type S []int`)
+	if AssignmentTypes(types.Typ[types.Int], s.GetType("T")) || !AssignmentTypes(types.NewSlice(types.Typ[types.Int]), s.GetType("S")) {
+		t.Error(`test failed`)
+	}
+	// an untyped value is not a constant
+	if !AssignmentTypes(types.Typ[types.UntypedNil], s.GetType("S")) || AssignmentTypes(types.Typ[types.UntypedBool], s.GetType("T")) {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,102 @@
+package gospec
+
+import (
+	"container/list"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// specCache holds the Specs checked by the package-level helpers, such as Assignment(code, v, t),
+// which are often called many times with the same code.
+var specCache = newSpecCache(64)
+
+// cachedSpec is like NewSpec, but returns the Spec checked before for the same code and opts, if it is cached.
+// The Spec must not be changed, it may be shared by the callers.
+func cachedSpec(code string, opts ...Option) *Spec {
+	s, err := cachedSpecE(code, opts...)
+	if err != nil {
+		log.Panic(err)
+	}
+	return s
+}
+
+// cachedSpecE is like cachedSpec, but returns an error instead of panicking, see NewSpecE.
+// The code which fails to check is not cached.
+func cachedSpecE(code string, opts ...Option) (*Spec, error) {
+	cfg := newConfig(opts)
+	normalized, _, _ := normalizeCode(code, cfg.packageName)
+	key := specKey{code: normalized, cfg: *cfg}
+	// options such as an importer of an uncomparable type can not be a key
+	if !reflect.ValueOf(key).Comparable() {
+		return NewSpecE(code, opts...)
+	}
+	if s := specCache.get(key); s != nil {
+		return s, nil
+	}
+	s, err := NewSpecE(code, opts...)
+	if err != nil {
+		return s, err
+	}
+	specCache.add(key, s)
+	return s, nil
+}
+
+type specKey struct {
+	code string // the normalized code
+	cfg  config
+}
+
+// lruSpecCache is a cache of Specs, which drops the least recently used Spec when it is full.
+type lruSpecCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // the elements of entries, the most recently used first
+	entries map[specKey]*list.Element
+}
+
+type specEntry struct {
+	key  specKey
+	spec *Spec
+}
+
+func newSpecCache(size int) *lruSpecCache {
+	return &lruSpecCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[specKey]*list.Element),
+	}
+}
+
+func (c *lruSpecCache) get(key specKey) *Spec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*specEntry).spec
+}
+
+func (c *lruSpecCache) add(key specKey, s *Spec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		// checked by another goroutine at the same time
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&specEntry{key: key, spec: s})
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*specEntry).key)
+	}
+}
+
+func (c *lruSpecCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
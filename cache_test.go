@@ -0,0 +1,72 @@
+package gospec
+
+import (
+	"go/types"
+	"testing"
+)
+
+// func cachedSpec(code string, opts ...Option) *Spec
+func Test_cachedSpec(t *testing.T) {
+	code := `type T int; var x T`
+	s := cachedSpec(code)
+	if cachedSpec(code) != s || cachedSpec(code, WithGoVersion("go1.17")) == s {
+		t.Error(`test failed`)
+	}
+	if cachedSpec(code, WithGoVersion("1.17")) != cachedSpec(code, WithGoVersion("go1.17")) {
+		t.Error(`test failed`)
+	}
+	// the code is normalized, so it is the same with the default package clause
+	if cachedSpec("package example\n"+code) != s || cachedSpec(code, WithPackageName("p")) == s {
+		t.Error(`test failed`)
+	}
+	// an uncomparable importer is not cached
+	imp := importerFunc(func(path string) (*types.Package, error) { return nil, nil })
+	if cachedSpec(code, WithImporter(imp)) == cachedSpec(code, WithImporter(imp)) {
+		t.Error(`test failed`)
+	}
+	if !Assignment(code, "x", "T") || Assignment(code, "x", "int") {
+		t.Error(`test failed`)
+	}
+}
+
+func Test_lruSpecCache(t *testing.T) {
+	c := newSpecCache(2)
+	keys := []specKey{{code: "a"}, {code: "b"}, {code: "c"}}
+	specs := []*Spec{new(Spec), new(Spec), new(Spec)}
+	c.add(keys[0], specs[0])
+	c.add(keys[1], specs[1])
+	if c.get(keys[0]) != specs[0] {
+		t.Error(`test failed`)
+	}
+	// b is the least recently used
+	c.add(keys[2], specs[2])
+	if c.len() != 2 || c.get(keys[1]) != nil || c.get(keys[0]) != specs[0] || c.get(keys[2]) != specs[2] {
+		t.Error(`test failed`)
+	}
+	c.add(keys[2], new(Spec))
+	if c.len() != 2 || c.get(keys[2]) != specs[2] {
+		t.Error(`test failed`)
+	}
+}
+
+const benchmarkCode = `import "fmt"; type T struct{ fmt.Stringer }; var x T`
+
+func BenchmarkAssignment(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Assignment(benchmarkCode, "x", "fmt.Stringer")
+	}
+}
+
+func BenchmarkAssignment_uncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewSpec(benchmarkCode).Assignment("x", "fmt.Stringer")
+	}
+}
+
+func BenchmarkAssignment_parallel(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Assignment(benchmarkCode, "x", "fmt.Stringer")
+		}
+	})
+}
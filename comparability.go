@@ -11,18 +11,30 @@ func GetDynamicTypeAtRuntime(i interface{}) reflect.Type {
 
 func (s *Spec) Comparable(v string) bool {
 	V := s.MustGetValidType(v)
-	return types.Comparable(V)
+	return mustAnswer(s.oracle.Comparable(s, V))
+}
+
+// ComparableE is like Comparable, but returns an error instead of panicking,
+// such as a *NotFoundError if v is not found.
+func (s *Spec) ComparableE(v string) (bool, error) {
+	V, err := s.getType(v)
+	if err != nil {
+		return false, err
+	}
+	return s.oracle.Comparable(s, V)
 }
 
 // Comparable(t types.Type) bool
 // or
 // Comparable(code, v string) bool
+//
+// ComparableTypes or ComparableIn check their arguments at compile time.
 func Comparable(a ...interface{}) bool {
 	switch len(a) {
 	case 1:
 		//t types.Type
 		if t, ok := a[0].(types.Type); ok {
-			return types.Comparable(t)
+			return ComparableTypes(t)
 		} else {
 			panic("args must types.Type")
 		}
@@ -33,10 +45,29 @@ func Comparable(a ...interface{}) bool {
 		if !ok1 || !ok2 {
 			panic("args must all string")
 		}
-		s := NewSpec(code)
-		return s.Comparable(v)
+		return ComparableIn(code, v)
 	default:
 		panic("unexpect")
 	}
 	return false
 }
+
+// ComparableTypes reports whether values of type t are comparable.
+func ComparableTypes(t types.Type) bool {
+	return types.Comparable(t)
+}
+
+// ComparableIn reports whether values of the type of v are comparable in code, see Spec.Comparable.
+func ComparableIn(code, v string) bool {
+	return cachedSpec(code).Comparable(v)
+}
+
+// ComparableInE is like ComparableIn, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.ComparableE.
+func ComparableInE(code, v string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.ComparableE(v)
+}
@@ -399,3 +399,13 @@ func TestComparable13(test *testing.T) {
 		test.Error("test failed")
 	}
 }
+
+// func ComparableTypes(t types.Type) bool
+func TestComparableTypes(test *testing.T) {
+	if !ComparableTypes(types.Typ[types.Int]) || ComparableTypes(types.NewSlice(types.Typ[types.Int])) {
+		test.Error(`test failed`)
+	}
+	if !ComparableIn(`type T struct{ a int }`, "T") || ComparableIn(`type T struct{ a []int }`, "T") {
+		test.Error(`test failed`)
+	}
+}
@@ -1,25 +1,234 @@
 package gospec
 
+import (
+	"go/constant"
+	"go/types"
+	"unicode"
+)
+
+// Conversion reports whether v can be converted to t.
+// v may be a name, or an expression such as "1.2"; t may be a name or a type expression.
 func (s *Spec) Conversion(v, t string) bool {
-	vo := s.MustGetValidTypeObject(v)
+	x := s.mustGetOperand(v)
+	T := s.MustGetValidType(t)
+	return mustAnswer(s.oracle.Convertible(s, Operand{x.typ, x.val}, T))
+}
+
+func Conversion(code, v, t string) bool {
+	s := cachedSpec(code)
+	return s.Conversion(v, t)
+}
+
+// ConversionE is like Conversion, but returns an error instead of panicking,
+// such as a *NotFoundError if v or t is not found.
+func (s *Spec) ConversionE(v, t string) (bool, error) {
+	x, err := s.getOperand(v)
+	if err != nil {
+		return false, err
+	}
+	T, err := s.getType(t)
+	if err != nil {
+		return false, err
+	}
+	return s.oracle.Convertible(s, Operand{x.typ, x.val}, T)
+}
+
+// ConversionE is like Conversion, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.ConversionE.
+func ConversionE(code, v, t string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.ConversionE(v, t)
+}
+
+// ConversionTypes reports whether a non-constant value of type v can be converted to type t.
+func ConversionTypes(v, t types.Type) bool {
+	_, ok := convert(v, nil, t, nil, "")
+	return ok
+}
+
+// ConversionValue is like Conversion, but also returns the constant result of converting v to t,
+// such as 0.5 for float32(0.49999999). The value is nil if the result is not a constant.
+func (s *Spec) ConversionValue(v, t string) (constant.Value, bool) {
+	x := s.mustGetOperand(v)
 	T := s.MustGetValidType(t)
+	return convert(x.typ, x.val, T, s.sizes, s.goVersion)
+}
+
+// convert reports whether a value of type V can be converted to T, val is the value of a constant, or nil.
+// It returns the constant result of the conversion, or nil if the result is not a constant,
+// see go/types.(*Checker).conversion. sizes is the same as assignable,
+// goVersion is the Go version which features are allowed, "" for all.
+func convert(V types.Type, val constant.Value, T types.Type, sizes types.Sizes, goVersion string) (constant.Value, bool) {
+	constConvertibleTo := func(T types.Type, result *constant.Value) bool {
+		switch t, _ := T.Underlying().(*types.Basic); {
+		case t == nil:
+		case representableConst(val, t, sizes, result):
+			return true
+		case IsInteger(V) && IsString(t):
+			codepoint := unicode.ReplacementChar
+			if i, ok := constant.Uint64Val(val); ok && i <= unicode.MaxRune {
+				codepoint = rune(i)
+			}
+			if result != nil {
+				*result = constant.MakeString(string(codepoint))
+			}
+			return true
+		}
+		return false
+	}
 
-	x := &operand{typ: vo.Type()}
-	if constObj, ok := ToConstObject(vo); ok {
-		x.mode = constant_
-		x.val = constObj.Val()
-	} else {
-		x.mode = value
+	switch {
+	case val != nil && IsConstType(T):
+		result := val
+		if !constConvertibleTo(T, &result) {
+			return nil, false
+		}
+		return result, true
+	case val != nil && isTypeParam(T):
+		// a constant is convertible to each specific type of T, the result is not a constant
+		return nil, underIs(T, func(u types.Type) bool {
+			if u == nil {
+				return false
+			}
+			return IsString(V) && isBytesOrRunes(u) || constConvertibleTo(u, nil)
+		})
+	default:
+		if !convertibleTo(V, val, T, sizes, goVersion) {
+			return nil, false
+		}
+		// an untyped constant converted to a type which is not a constant type, such as interface{}, has its default type,
+		// go/types reports it when the type of the expression is updated, not in (*Checker).conversion
+		if val != nil {
+			if d, ok := types.Default(V).(*types.Basic); ok && IsUntyped(V) && !representableConst(val, d, sizes, nil) {
+				return nil, false
+			}
+		}
+		return nil, true
 	}
+}
 
-	_conversion(s.checker, x, T)
-	if x.mode > 0 {
+// convertibleTo reports whether a non-constant value of type V can be converted to T by the rules of the spec:
+//
+//	it is assignable to T.
+//	ignoring struct tags, V and T have identical underlying types, and they are not type parameters.
+//	ignoring struct tags, V and T are pointer types that are not named types,
+//	and their pointer base types have identical underlying types, and they are not type parameters.
+//	V and T are both integer or floating point types.
+//	V and T are both complex types.
+//	V is an integer or a slice of bytes or runes, and T is a string type.
+//	V is a string type, and T is a slice of bytes or runes.
+//	V is a slice, T is an array (since go1.20) or a pointer to an array (since go1.17),
+//	and they have identical element types.
+//	V is a pointer or uintptr, and T is unsafe.Pointer, or vice versa.
+//
+// and the rules for type parameters: they are applied to each specific type in the type set of them.
+func convertibleTo(V types.Type, val constant.Value, T types.Type, sizes types.Sizes, goVersion string) bool {
+	if assignableTo(V, val, T, sizes) {
 		return true
 	}
+	// the operand of a shift, such as 1 of 1 << s, has the type it is converted to, as it is assigned
+	if b, ok := V.(*types.Basic); ok && val == nil && (b.Kind() == types.UntypedInt || b.Kind() == types.UntypedRune) {
+		return false
+	}
+
+	V = types.Unalias(V)
+	T = types.Unalias(T)
+	Vu := V.Underlying()
+	Tu := T.Underlying()
+	Vp, _ := V.(*types.TypeParam)
+	Tp, _ := T.(*types.TypeParam)
+
+	if types.IdenticalIgnoreTags(Vu, Tu) && Vp == nil && Tp == nil {
+		return true
+	}
+
+	if V, ok := V.(*types.Pointer); ok {
+		if T, ok := T.(*types.Pointer); ok {
+			if types.IdenticalIgnoreTags(V.Elem().Underlying(), T.Elem().Underlying()) &&
+				!isTypeParam(V.Elem()) && !isTypeParam(T.Elem()) {
+				return true
+			}
+		}
+	}
+
+	if (IsInteger(Vu) || IsFloat(Vu)) && (IsInteger(Tu) || IsFloat(Tu)) {
+		return true
+	}
+
+	if IsComplex(Vu) && IsComplex(Tu) {
+		return true
+	}
+
+	if (IsInteger(Vu) || isBytesOrRunes(Vu)) && IsString(Tu) {
+		return true
+	}
+
+	if IsString(Vu) && isBytesOrRunes(Tu) {
+		return true
+	}
+
+	if (isPointer(Vu) || isUintptr(Vu)) && isUnsafePointer(Tu) {
+		return true
+	}
+	if isUnsafePointer(Vu) && (isPointer(Tu) || isUintptr(Tu)) {
+		return true
+	}
+
+	if s, _ := Vu.(*types.Slice); s != nil {
+		switch a := Tu.(type) {
+		case *types.Array:
+			if types.Identical(s.Elem(), a.Elem()) {
+				return allowVersion(goVersion, "go1.20")
+			}
+		case *types.Pointer:
+			if a, _ := a.Elem().Underlying().(*types.Array); a != nil {
+				if types.Identical(s.Elem(), a.Elem()) {
+					return allowVersion(goVersion, "go1.17")
+				}
+			}
+		}
+	}
+
+	switch {
+	case Vp != nil && Tp != nil:
+		return typeSetIs(Vp, func(v *types.Term) bool {
+			return v != nil && typeSetIs(Tp, func(t *types.Term) bool {
+				return t != nil && convertibleTo(v.Type(), val, t.Type(), sizes, goVersion)
+			})
+		})
+	case Vp != nil:
+		return typeSetIs(Vp, func(v *types.Term) bool {
+			return v != nil && convertibleTo(v.Type(), val, T, sizes, goVersion)
+		})
+	case Tp != nil:
+		return typeSetIs(Tp, func(t *types.Term) bool {
+			return t != nil && convertibleTo(V, val, t.Type(), sizes, goVersion)
+		})
+	}
 	return false
 }
 
-func Conversion(code, v, t string) bool {
-	s := NewSpec(code)
-	return s.Conversion(v, t)
+func isUintptr(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uintptr
+}
+
+func isUnsafePointer(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.UnsafePointer
+}
+
+func isPointer(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Pointer)
+	return ok
+}
+
+func isBytesOrRunes(t types.Type) bool {
+	if s, ok := t.Underlying().(*types.Slice); ok {
+		return IsByte(s.Elem()) || IsRune(s.Elem())
+	}
+	return false
 }
@@ -3,6 +3,7 @@ package gospec
 import (
 	"errors"
 	"fmt"
+	"go/types"
 	"testing"
 )
 
@@ -282,3 +283,86 @@ func TestConversion03(t *testing.T) {
 		t.Errorf("test failed")
 	}
 }
+
+// the rules engine must agree with go/types.(*Checker).conversion, the constant results too
+func TestConversionMatchesChecker(t *testing.T) {
+	corpus := map[string][2][]string{
+		"":       {assignmentValues, assignmentTypes},
+		"go1.19": {assignmentValues, assignmentTypes},
+		// generics are not allowed before go1.18
+		"go1.16": {[]string{"vInts", "vBytes", "vString"}, []string{"[2]int", "*[2]int", "*[3]int", "string"}},
+		"go1.17": {[]string{"vInts", "vBytes", "vString"}, []string{"[2]int", "*[2]int", "*[3]int", "string"}},
+	}
+	for version, corpus := range corpus {
+		code := assignmentCorpus
+		if !allowVersion(version, "go1.18") {
+			code = `var vInts []int; var vBytes []byte; var vString string`
+		}
+		s := NewSpec(code, WithSearchKind(SearchAll), WithGoVersion(version))
+		for _, v := range corpus[0] {
+			for _, typ := range corpus[1] {
+				wantVal, want, err := linkConversion(s.checker, s.mustGetOperand(v), s.MustGetValidType(typ))
+				if err != nil {
+					t.Fatal(err)
+				}
+				// _conversion leaves the default type of an untyped constant to the checking of the enclosing expression
+				if x := s.mustGetOperand(v); want && wantVal == nil && x.val != nil && IsUntyped(x.typ) {
+					want = representableConst(x.val, types.Default(x.typ).(*types.Basic), nil, nil)
+				}
+				val, ok := s.ConversionValue(v, typ)
+				if ok != want {
+					t.Errorf("%s: Conversion(%s, %s) should be %v", version, v, typ, want)
+				} else if (wantVal != nil) != (val != nil) || val != nil && wantVal.ExactString() != val.ExactString() {
+					t.Errorf("%s: ConversionValue(%s, %s) should be %v, not %v", version, v, typ, wantVal, val)
+				}
+			}
+		}
+	}
+}
+
+// func (s *Spec) ConversionValue(v, t string) (constant.Value, bool)
+func TestSpec_ConversionValue(t *testing.T) {
+	s := NewSpec(`type MyString string; var s []int`)
+	infos := []struct {
+		x, T  string
+		value string
+		ok    bool
+	}{
+		{`float32(0.49999999)`, `float32`, `0.5`, true},
+		{`0.49999999`, `float32`, `0.5`, true},
+		{`0x266c`, `string`, `"♬"`, true},
+		{`-1`, `string`, `"�"`, true},
+		{`"foo" + "bar"`, `MyString`, `"foobar"`, true},
+		{`1.2`, `int`, ``, false},
+		{`s`, `*[2]int`, ``, true},
+		{`"foo"`, `[]byte`, ``, true},
+	}
+	for _, v := range infos {
+		val, ok := s.ConversionValue(v.x, v.T)
+		if ok != v.ok || val == nil && v.value != "" || val != nil && val.String() != v.value {
+			t.Errorf("ConversionValue(%s, %s) is %v, %v", v.x, v.T, val, ok)
+		}
+	}
+}
+
+// func ConversionTypes(v, t types.Type) bool
+func TestConversionTypes(t *testing.T) {
+	s := NewSpec(`type T int; type S []int`)
+	if !ConversionTypes(types.Typ[types.Float64], s.GetType("T")) || ConversionTypes(types.Typ[types.String], s.GetType("S")) {
+		t.Error(`test failed`)
+	}
+	if !ConversionTypes(types.Typ[types.String], types.NewSlice(types.Typ[types.Byte])) {
+		t.Error(`test failed`)
+	}
+}
+
+// the operand of a non-constant shift is given the type it is converted to, which must be an integer type
+func TestConversion04(t *testing.T) {
+	code := "var s uint"
+	if !Conversion(code, "1 << s", "uint64") || !Conversion(code, "1.0 << s", "rune") || !Conversion(code, "1 << s", "interface{}") {
+		t.Error(`test failed`)
+	}
+	if Conversion(code, "1 << s", "string") || Conversion(code, "'a' << s", "string") || Conversion(code, "1 << s", "*int") {
+		t.Error(`test failed`)
+	}
+}
@@ -4,12 +4,23 @@ import "go/types"
 
 func (s *Spec) IsDefinedType(v string) bool {
 	t := s.GetType(v)
-	return isNamed(t)
+	return hasName(t)
+}
+
+// IsDefinedTypeE is like IsDefinedType, but returns a *NotFoundError if v is not found.
+func (s *Spec) IsDefinedTypeE(v string) (bool, error) {
+	t, err := s.getType(v)
+	if err != nil {
+		return false, err
+	}
+	return hasName(t), nil
 }
 
 //IsDefinedType(t types.Type) bool
 //or
 //IsDefinedType(code,v string) bool
+//
+// IsDefinedTypeTypes or IsDefinedTypeIn check their arguments at compile time.
 func IsDefinedType(a ...interface{}) bool {
 	switch len(a) {
 	case 1:
@@ -17,7 +28,7 @@ func IsDefinedType(a ...interface{}) bool {
 		if !ok {
 			panic("arg must be a types.Type")
 		}
-		return isNamed(t)
+		return IsDefinedTypeTypes(t)
 	case 2:
 		code, ok1 := a[0].(string)
 		v, ok2 := a[1].(string)
@@ -25,10 +36,38 @@ func IsDefinedType(a ...interface{}) bool {
 			panic("args must all string")
 
 		}
-		s := NewSpec(code)
-		return s.IsDefinedType(v)
+		return IsDefinedTypeIn(code, v)
 	default:
 		panic("unexpect")
 	}
 	return true
 }
+
+// IsDefinedTypeTypes reports whether t has a name, like IsDefinedType(t).
+func IsDefinedTypeTypes(t types.Type) bool {
+	return hasName(t)
+}
+
+// IsDefinedTypeIn reports whether the type of v has a name in code, see Spec.IsDefinedType.
+func IsDefinedTypeIn(code, v string) bool {
+	return cachedSpec(code).IsDefinedType(v)
+}
+
+// IsDefinedTypeInE is like IsDefinedTypeIn, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.IsDefinedTypeE.
+func IsDefinedTypeInE(code, v string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.IsDefinedTypeE(v)
+}
+
+// hasName reports whether t has a name: it is a predeclared type, a defined type or a type parameter.
+func hasName(t types.Type) bool {
+	switch types.Unalias(t).(type) {
+	case *types.Basic, *types.Named, *types.TypeParam:
+		return true
+	}
+	return false
+}
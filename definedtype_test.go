@@ -55,3 +55,14 @@ func TestIsDefinedType06(t *testing.T) {
 		t.Error(`test rule failed`)
 	}
 }
+
+// func IsDefinedTypeTypes(t types.Type) bool
+func TestIsDefinedTypeTypes(t *testing.T) {
+	s := NewSpec(`type A int; type B = []A`)
+	if !IsDefinedTypeTypes(s.GetType("A")) || IsDefinedTypeTypes(s.GetType("B")) {
+		t.Error(`test failed`)
+	}
+	if !IsDefinedTypeIn(`type A int`, "A") || IsDefinedTypeIn(`type B = []int`, "B") {
+		t.Error(`test failed`)
+	}
+}
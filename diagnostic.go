@@ -0,0 +1,74 @@
+package gospec
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+)
+
+// Diagnostic is an error found in the code, with its position mapped back
+// into the code as it was given (without the package head added by NewSpec and NewSpecFromFiles).
+type Diagnostic struct {
+	Pos  token.Position
+	Msg  string
+	Soft bool // soft errors, such as unused variables or imports, do not affect type information
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Msg)
+}
+
+// Diagnostics returns every error the type checker reported for the code of s.
+func (s *Spec) Diagnostics() []Diagnostic {
+	errs := s.Errors()
+	ds := make([]Diagnostic, 0, len(errs))
+	for _, e := range errs {
+		ds = append(ds, Diagnostic{
+			Pos:  s.position(e.Fset.Position(e.Pos)),
+			Msg:  e.Msg,
+			Soft: e.Soft,
+		})
+	}
+	return ds
+}
+
+// Compiles reports whether the code of s is a legal Go program, that is, the checker reported no error at all.
+func (s *Spec) Compiles() bool {
+	return len(s.Errors()) == 0
+}
+
+// Diagnostics returns every syntax or type error in code.
+// As for Compiles, the locals of statements are not reported as declared and not used.
+func Diagnostics(code string, opts ...Option) []Diagnostic {
+	s, err := NewSpecE(code, opts...)
+	if s != nil {
+		return s.Diagnostics()
+	}
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return []Diagnostic{{Msg: err.Error()}}
+	}
+	src, _ := newSourceFile("", code, newConfig(opts).packageName)
+	ds := make([]Diagnostic, 0, len(list))
+	for _, e := range list {
+		ds = append(ds, Diagnostic{Pos: src.originalPosition(e.Pos), Msg: e.Msg})
+	}
+	return ds
+}
+
+// Compiles reports whether code is a legal Go program.
+// The locals of statements, such as x of "x := 1", are used by the code wrapping them, see NewSpec,
+// so they are not reported as declared and not used.
+func Compiles(code string, opts ...Option) bool {
+	_, err := NewSpecE(code, opts...)
+	return err == nil
+}
+
+// position maps pos back into the source file it belongs to.
+func (s *Spec) position(pos token.Position) token.Position {
+	if src := s.source(pos.Filename); src != nil {
+		return src.originalPosition(pos)
+	}
+	return pos
+}
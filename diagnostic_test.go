@@ -0,0 +1,61 @@
+package gospec
+
+import "testing"
+
+// func (s *Spec) Diagnostics() []Diagnostic
+func TestSpec_Diagnostics(t *testing.T) {
+	s, _ := NewSpecE(`
+func main() {
+	var a int = "x"
+	b := 1
+	_ = a
+}`)
+	ds := s.Diagnostics()
+	if len(ds) != 2 || s.Compiles() {
+		t.Fatal(`test failed`)
+	}
+	if ds[0].Pos.Line != 3 || ds[0].Pos.Column != 14 || ds[0].Soft ||
+		ds[1].Pos.Line != 4 || ds[1].Pos.Column != 2 || !ds[1].Soft ||
+		ds[1].String() != "example.go:4:2: declared and not used: b" {
+		t.Error(`test failed`)
+	}
+
+	// code with its own package clause is not shifted
+	s, _ = NewSpecE(`package haha
+var a int = "x"`)
+	ds = s.Diagnostics()
+	if len(ds) != 1 || ds[0].Pos.Filename != "haha.go" || ds[0].Pos.Line != 2 || ds[0].Pos.Column != 13 {
+		t.Error(`test failed`)
+	}
+
+	// the package clause inserted after a build constraint does not shift positions
+	s, _ = NewSpecE(`//go:build linux
+
+// a is a number
+var a int = "x"`)
+	ds = s.Diagnostics()
+	if len(ds) != 1 || ds[0].Pos.Line != 4 || ds[0].Pos.Column != 13 {
+		t.Error(`test failed`)
+	}
+
+	s, _ = NewSpecE(`var a int = 1`)
+	if len(s.Diagnostics()) != 0 || !s.Compiles() {
+		t.Error(`test failed`)
+	}
+}
+
+// func Diagnostics(code string) []Diagnostic
+// func Compiles(code string) bool
+func TestDiagnostics(t *testing.T) {
+	ds := Diagnostics(`var a int = `)
+	if len(ds) != 1 || ds[0].Pos.Line != 1 || ds[0].Pos.Column != 13 {
+		t.Error(`test failed`)
+	}
+	ds = Diagnostics(`var a, b int = 1, "x"`)
+	if len(ds) != 1 || ds[0].Pos.Line != 1 || ds[0].Pos.Column != 19 {
+		t.Error(`test failed`)
+	}
+	if Compiles(`var a int = `) || Compiles(`func f() { a := 1 }`) || !Compiles(`var a = 1`) {
+		t.Error(`test failed`)
+	}
+}
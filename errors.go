@@ -0,0 +1,95 @@
+package gospec
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// ErrIncompatibleToolchain is returned when the go/types internals this package links to
+// do not match the ones of the toolchain which compiled it.
+var ErrIncompatibleToolchain = errors.New("incompatible go/types")
+
+// CheckError is returned when the code fails to type-check.
+// Errors holds every error the checker reported, hard and soft ones.
+type CheckError struct {
+	Errors []types.Error
+}
+
+func (e *CheckError) Error() string {
+	switch len(e.Errors) {
+	case 0:
+		return "check file failed"
+	case 1:
+		return fmt.Sprintf("check file failed: %s", e.Errors[0])
+	default:
+		return fmt.Sprintf("check file failed: %s (and %d more errors)", e.Errors[0], len(e.Errors)-1)
+	}
+}
+
+// HasHardErrors reports whether any of the errors is not a soft error.
+// Soft errors (such as unused variables or imports) do not affect the type information of the code.
+func (e *CheckError) HasHardErrors() bool {
+	for _, err := range e.Errors {
+		if !err.Soft {
+			return true
+		}
+	}
+	return false
+}
+
+// AmbiguityError is returned when a name is declared in several scopes of the code, see LookupStrict.
+type AmbiguityError struct {
+	Name       string
+	Candidates []ObjectInfo
+}
+
+func (e *AmbiguityError) Error() string {
+	places := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		places[i] = fmt.Sprintf("%d:%d", c.Pos.Line, c.Pos.Column)
+		if c.Func != "" {
+			places[i] += " in " + c.Func
+		}
+	}
+	return fmt.Sprintf("<%s> is ambiguous, it is declared at %s", e.Name, strings.Join(places, ", "))
+}
+
+// DisagreementError is returned by the RelationOracle of CrossCheck, when its oracles A and B disagree.
+type DisagreementError struct {
+	Query string // such as "Assignable(value of type int, int64)"
+	A, B  bool
+}
+
+func (e *DisagreementError) Error() string {
+	return fmt.Sprintf("oracles disagree on %s: %v and %v", e.Query, e.A, e.B)
+}
+
+// NotFoundError is returned when a name or an expression is not found in the code.
+type NotFoundError struct {
+	Name string
+	Code string // the normalized code
+}
+
+func (e *NotFoundError) Error() string {
+	return "find <" + e.Name + "> in code <" + e.Code + "> failed"
+}
+
+// NotConstantError is returned when a constant is expected, but Expr is not a constant.
+type NotConstantError struct {
+	Expr string
+}
+
+func (e *NotConstantError) Error() string {
+	return "<" + e.Expr + "> is not a constant"
+}
+
+// NotTypeError is returned when a type is expected, but the expression Expr is a value.
+type NotTypeError struct {
+	Expr string
+}
+
+func (e *NotTypeError) Error() string {
+	return "<" + e.Expr + "> is not a type"
+}
@@ -0,0 +1,95 @@
+package gospec
+
+import (
+	"errors"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestCheckError(t *testing.T) {
+	hard := types.Error{Msg: "hard"}
+	soft := types.Error{Msg: "soft", Soft: true}
+
+	if (&CheckError{}).Error() != "check file failed" {
+		t.Error(`test failed`)
+	}
+	e := &CheckError{Errors: []types.Error{soft}}
+	if !strings.HasSuffix(e.Error(), "soft") || e.HasHardErrors() {
+		t.Error(`test failed`)
+	}
+	e = &CheckError{Errors: []types.Error{soft, hard}}
+	if !strings.HasSuffix(e.Error(), "soft (and 1 more errors)") || !e.HasHardErrors() {
+		t.Error(`test failed`)
+	}
+}
+
+// the E variants of the relations return the errors instead of panicking
+func TestRelationErrors(t *testing.T) {
+	code := `type T int; const c = 1 << 10; var x T`
+	s := NewSpec(code)
+
+	var notFound *NotFoundError
+	if _, err := s.AssignmentE("y", "T"); !errors.As(err, &notFound) || notFound.Name != "y" {
+		t.Error(`test failed`)
+	}
+	for _, query := range []func() (bool, error){
+		func() (bool, error) { return s.IdenticalE("T", "U") },
+		func() (bool, error) { return s.ConversionE("x", "[]U") },
+		func() (bool, error) { return s.ComparableE("U") },
+		func() (bool, error) { return s.ImplementsE("U", "T") },
+		func() (bool, error) { return s.IsDefinedTypeE("U") },
+		func() (bool, error) { return s.RepresentableE("c", "U") },
+	} {
+		if ok, err := query(); ok || !errors.As(err, &notFound) {
+			t.Error(`test failed`)
+		}
+	}
+	var notConstant *NotConstantError
+	if _, err := s.RepresentableE("x", "int8"); !errors.As(err, &notConstant) || notConstant.Expr != "x" {
+		t.Error(`test failed`)
+	}
+	var notType *NotTypeError
+	if _, err := s.AssignmentE("x", "c + 1"); !errors.As(err, &notType) || notType.Expr != "c + 1" {
+		t.Error(`test failed`)
+	}
+	var ambiguity *AmbiguityError
+	if _, err := NewSpec(`func f() { var a int; _ = a }; func g() { var a int; _ = a }`, WithSearchKind(SearchAllStrict)).ComparableE("a"); !errors.As(err, &ambiguity) {
+		t.Error(`test failed`)
+	}
+
+	if ok, err := s.AssignmentE("c", "T"); err != nil || !ok {
+		t.Error(`test failed`)
+	}
+	if ok, err := s.RepresentableE("c", "int8"); err != nil || ok {
+		t.Error(`test failed`)
+	}
+	if ok, err := s.IsDefinedTypeE("[]T"); err != nil || ok {
+		t.Error(`test failed`)
+	}
+
+	var checkErr *CheckError
+	if _, err := AssignmentE(`var x T`, "x", "int"); !errors.As(err, &checkErr) {
+		t.Error(`test failed`)
+	}
+	if ok, err := AssignmentE(code, "c", "T"); err != nil || !ok {
+		t.Error(`test failed`)
+	}
+	for _, query := range []func() (bool, error){
+		func() (bool, error) { return IdenticalInE(code, "T", "x") },
+		func() (bool, error) { return ConversionE(code, "x", "int") },
+		func() (bool, error) { return ComparableInE(code, "T") },
+		func() (bool, error) { return RepresentableE(code, "c", "int16") },
+		func() (bool, error) { return IsDefinedTypeInE(code, "T") },
+	} {
+		if ok, err := query(); err != nil || !ok {
+			t.Error(`test failed`)
+		}
+	}
+	if _, err := ImplementsInE(code, "T", "y"); !errors.As(err, &notFound) {
+		t.Error(`test failed`)
+	}
+	if (&NotFoundError{Name: "y", Code: code}).Error() != "find <y> in code <"+code+"> failed" {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,340 @@
+package gospec
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// eval evaluates the expression expr as if it appeared at the end of the code of s,
+// where the imports of its files are visible. The locals of wrapped statements are visible too.
+func (s *Spec) eval(expr string) (types.TypeAndValue, error) {
+	if _, err := parser.ParseExpr(expr); err != nil {
+		return types.TypeAndValue{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tv types.TypeAndValue
+	var err error
+	// the wrapped statements first, their locals hide the names of the package
+	for _, stmts := range []bool{true, false} {
+		for i := range s.files {
+			if s.sources[i].head.stmts != stmts {
+				continue
+			}
+			tv, err = s.evalIn(i, expr)
+			if err == nil {
+				return tv, nil
+			}
+		}
+	}
+	return tv, err
+}
+
+// evalIn evaluates expr in a statement appended to the file i of s. The files of s are checked again
+// with the statement into a new package by the config of s, so a query never changes the scopes of s,
+// and the types of the new package are mapped back to the ones of s, see scratchTypes.
+func (s *Spec) evalIn(i int, expr string) (types.TypeAndValue, error) {
+	fset := s.scratchFileSet()
+	// parsed after the files of s, the statement is after all of their declarations
+	q, err := parser.ParseFile(fset, s.sources[i].name, "package _\nfunc _() {\n"+expr+"\n}\n", 0)
+	if err != nil {
+		return types.TypeAndValue{}, err
+	}
+	fn := q.Decls[0].(*ast.FuncDecl)
+	stmt := fn.Body.List[len(fn.Body.List)-1]
+	x := stmt.(*ast.ExprStmt).X
+
+	files := make([]*ast.File, len(s.files))
+	for j, f := range s.files {
+		g := *f
+		g.Decls = make([]ast.Decl, len(f.Decls))
+		for k, d := range f.Decls {
+			if d, ok := d.(*ast.FuncDecl); ok {
+				// the bodies of functions do not change the types of the package, they are not checked again,
+				// but the one wrapping the statements of the file, whose locals are visible, see eval
+				fd := *d
+				fd.Body = nil
+				if j == i && k == 0 && s.sources[i].head.stmts {
+					body := *d.Body
+					body.List = append(append([]ast.Stmt{}, d.Body.List...), stmt)
+					fd.Body = &body
+				}
+				g.Decls[k] = &fd
+				continue
+			}
+			g.Decls[k] = d
+		}
+		if j == i && !s.sources[i].head.stmts {
+			g.Decls = append(g.Decls, fn)
+		}
+		files[j] = &g
+	}
+
+	var errs []types.Error
+	conf := &types.Config{
+		Importer:  s.importer,
+		GoVersion: s.goVersion,
+		Sizes:     s.sizes,
+		Error: func(err error) {
+			e, ok := err.(types.Error)
+			if !ok || e.Pos < x.Pos() || e.Pos >= x.End() {
+				return
+			}
+			// an expression which is not a call is reported as a statement
+			if e.Pos == x.Pos() && (strings.HasSuffix(e.Msg, " is not used") ||
+				strings.HasSuffix(e.Msg, " must be called") || strings.HasSuffix(e.Msg, " is not an expression")) {
+				return
+			}
+			errs = append(errs, e)
+		},
+	}
+	pkg := types.NewPackage(s.pkg.Path(), s.pkg.Name())
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	_ = types.NewChecker(conf, fset, pkg, info).Files(files)
+	if len(errs) > 0 {
+		return types.TypeAndValue{}, errs[0]
+	}
+	tv, ok := info.Types[x]
+	if !ok {
+		return types.TypeAndValue{}, fmt.Errorf("%s is not checked", expr)
+	}
+	tv.Type = scratchTypes{s: s, pkg: pkg}.typ(tv.Type)
+	return tv, nil
+}
+
+// scratchTypes maps the types of pkg, a package checked again from the files of s, to the types of s.
+type scratchTypes struct {
+	s   *Spec
+	pkg *types.Package
+}
+
+// object returns the object of s declared as o, or nil if o is not declared in the files of s,
+// such as a type declared in the evaluated expression.
+func (m scratchTypes) object(o types.Object) types.Object {
+	if o.Parent() == m.pkg.Scope() {
+		return m.s.pkg.Scope().Lookup(o.Name())
+	}
+	// a local has the position of its declaration in the files of s
+	for _, so := range m.s.info.Defs {
+		if so != nil && so.Pos() == o.Pos() && so.Name() == o.Name() {
+			return so
+		}
+	}
+	return nil
+}
+
+func (m scratchTypes) pkgOf(p *types.Package) *types.Package {
+	if p == m.pkg {
+		return m.s.pkg
+	}
+	return p
+}
+
+func (m scratchTypes) typ(t types.Type) types.Type {
+	switch t := t.(type) {
+	case *types.Named:
+		return m.named(t, t.Obj(), t.TypeArgs())
+	case *types.Alias:
+		return m.named(t, t.Obj(), nil)
+	case *types.TypeParam:
+		if o := m.object(t.Obj()); o != nil && t.Obj().Pkg() == m.pkg {
+			return o.Type()
+		}
+	case *types.Pointer:
+		return types.NewPointer(m.typ(t.Elem()))
+	case *types.Slice:
+		return types.NewSlice(m.typ(t.Elem()))
+	case *types.Array:
+		return types.NewArray(m.typ(t.Elem()), t.Len())
+	case *types.Map:
+		return types.NewMap(m.typ(t.Key()), m.typ(t.Elem()))
+	case *types.Chan:
+		return types.NewChan(t.Dir(), m.typ(t.Elem()))
+	case *types.Tuple:
+		return m.tuple(t)
+	case *types.Signature:
+		return types.NewSignatureType(nil, nil, nil, m.tuple(t.Params()), m.tuple(t.Results()), t.Variadic())
+	case *types.Struct:
+		fields := make([]*types.Var, t.NumFields())
+		tags := make([]string, t.NumFields())
+		for i := range fields {
+			f := t.Field(i)
+			fields[i] = types.NewField(f.Pos(), m.pkgOf(f.Pkg()), f.Name(), m.typ(f.Type()), f.Embedded())
+			tags[i] = t.Tag(i)
+		}
+		return types.NewStruct(fields, tags)
+	case *types.Interface:
+		methods := make([]*types.Func, t.NumExplicitMethods())
+		for i := range methods {
+			f := t.ExplicitMethod(i)
+			methods[i] = types.NewFunc(f.Pos(), m.pkgOf(f.Pkg()), f.Name(), m.typ(f.Type()).(*types.Signature))
+		}
+		embeddeds := make([]types.Type, t.NumEmbeddeds())
+		for i := range embeddeds {
+			embeddeds[i] = m.typ(t.EmbeddedType(i))
+		}
+		return types.NewInterfaceType(methods, embeddeds).Complete()
+	case *types.Union:
+		terms := make([]*types.Term, t.Len())
+		for i := range terms {
+			terms[i] = types.NewTerm(t.Term(i).Tilde(), m.typ(t.Term(i).Type()))
+		}
+		return types.NewUnion(terms)
+	}
+	return t
+}
+
+// named maps the named type or alias t of obj, instantiated with args.
+func (m scratchTypes) named(t types.Type, obj *types.TypeName, args *types.TypeList) types.Type {
+	if obj.Pkg() != m.pkg {
+		return t
+	}
+	o := m.object(obj)
+	if o == nil {
+		return t
+	}
+	if args.Len() == 0 {
+		return o.Type()
+	}
+	targs := make([]types.Type, args.Len())
+	for i := range targs {
+		targs[i] = m.typ(args.At(i))
+	}
+	inst, err := types.Instantiate(nil, o.Type(), targs, false)
+	if err != nil {
+		return t
+	}
+	return inst
+}
+
+func (m scratchTypes) tuple(t *types.Tuple) *types.Tuple {
+	if t == nil {
+		return nil
+	}
+	vars := make([]*types.Var, t.Len())
+	for i := range vars {
+		v := t.At(i)
+		vars[i] = types.NewParam(v.Pos(), m.pkgOf(v.Pkg()), v.Name(), m.typ(v.Type()))
+	}
+	return types.NewTuple(vars...)
+}
+
+// evalType evaluates the type expression expr, it returns nil if expr is not a valid type expression.
+func (s *Spec) evalType(expr string) types.Type {
+	tv, err := s.eval(expr)
+	if err != nil || !tv.IsType() {
+		return nil
+	}
+	return tv.Type
+}
+
+// ExprMode is the mode of an expression, like the operand modes of go/types.
+type ExprMode int
+
+const (
+	ModeInvalid  ExprMode = iota // the expression is invalid
+	ModeNoValue                  // a call of a function without result
+	ModeBuiltin                  // a built-in function
+	ModeTypExpr                  // a type
+	ModeConstant                 // a constant
+	ModeVariable                 // an addressable variable
+	ModeValue                    // a computed value
+)
+
+var exprModeString = [...]string{
+	ModeInvalid:  "invalid",
+	ModeNoValue:  "novalue",
+	ModeBuiltin:  "builtin",
+	ModeTypExpr:  "typexpr",
+	ModeConstant: "constant",
+	ModeVariable: "variable",
+	ModeValue:    "value",
+}
+
+func (m ExprMode) String() string {
+	if m < 0 || int(m) >= len(exprModeString) {
+		return "invalid"
+	}
+	return exprModeString[m]
+}
+
+// TypeOf returns the type of the expression expr, such as "1 << 10 + c" or "len(arr)",
+// evaluated in the package scope. It returns nil if expr is invalid.
+func (s *Spec) TypeOf(expr string) types.Type {
+	tv, err := s.eval(expr)
+	if err != nil {
+		return nil
+	}
+	return tv.Type
+}
+
+// ConstValue returns the value of the constant expression expr, evaluated in the package scope.
+// It returns nil if expr is invalid or not a constant.
+func (s *Spec) ConstValue(expr string) constant.Value {
+	tv, err := s.eval(expr)
+	if err != nil {
+		return nil
+	}
+	return tv.Value
+}
+
+// Mode returns the mode of the expression expr, evaluated in the package scope.
+func (s *Spec) Mode(expr string) ExprMode {
+	tv, err := s.eval(expr)
+	switch {
+	case err != nil:
+		return ModeInvalid
+	case tv.IsVoid():
+		return ModeNoValue
+	case tv.IsBuiltin():
+		return ModeBuiltin
+	case tv.IsType():
+		return ModeTypExpr
+	case tv.Value != nil:
+		return ModeConstant
+	case tv.Addressable():
+		return ModeVariable
+	case tv.IsValue():
+		return ModeValue
+	default:
+		return ModeInvalid
+	}
+}
+
+// mustGetOperand returns the operand of v, which is the name of an object, see GetTypeObject,
+// or an expression evaluated in the package scope.
+func (s *Spec) mustGetOperand(v string) *operand {
+	x, err := s.getOperand(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	return x
+}
+
+// getOperand is like mustGetOperand, but returns an error instead of panicking.
+func (s *Spec) getOperand(v string) (*operand, error) {
+	if o := s.GetTypeObject(v); o != nil {
+		x := &operand{mode: value, typ: o.Type()}
+		if constObj, ok := ToConstObject(o); ok {
+			x.mode = constant_
+			x.val = constObj.Val()
+		}
+		return x, nil
+	}
+	if !token.IsIdentifier(v) {
+		if tv, err := s.eval(v); err == nil && tv.IsValue() {
+			x := &operand{mode: value, typ: tv.Type}
+			if tv.Value != nil {
+				x.mode = constant_
+				x.val = tv.Value
+			}
+			return x, nil
+		}
+	}
+	return nil, s.notFound(v)
+}
@@ -0,0 +1,151 @@
+package gospec
+
+import (
+	"go/types"
+	"testing"
+)
+
+// func (s *Spec) evalType(expr string) types.Type
+func TestSpec_evalType(t *testing.T) {
+	s := NewSpec(`
+import "fmt"
+type T int
+type F = func(int) error
+var x []map[string]*T
+var y = fmt.Sprint()
+`)
+	for expr, want := range map[string]string{
+		"[]map[string]*T":          "[]map[string]*example.T",
+		"func(int) error":          "func(int) error",
+		"struct{ S fmt.Stringer }": "struct{S fmt.Stringer}",
+		"T":                        "example.T",
+		"x":                        "",
+		"1 + 2":                    "",
+		"[]NotExists":              "",
+	} {
+		typ := s.evalType(expr)
+		if (typ == nil && want != "") || (typ != nil && typ.String() != want) {
+			t.Errorf("eval %s got %v, want %q", expr, typ, want)
+		}
+	}
+
+	// type expressions are accepted wherever a type name is expected
+	if !s.Assignment("x", "[]map[string]*T") || !s.Identical("func(int) error", "F") ||
+		s.Identical("func(int) error", "func(int64) error") || !s.Conversion("y", "[]byte") ||
+		s.GetUnderlyingType("[]T").String() != "[]example.T" || s.GetBaseType("*[]T").String() != "[]example.T" {
+		t.Error(`test failed`)
+	}
+}
+
+// func (s *Spec) TypeOf(expr string) types.Type
+// func (s *Spec) ConstValue(expr string) constant.Value
+// func (s *Spec) Mode(expr string) ExprMode
+func TestSpec_TypeOf(t *testing.T) {
+	s := NewSpec(`
+import "fmt"
+const c = 1
+var arr [4]int
+var m map[string]int
+func f() {}
+func g() int { return 0 }
+var _ = fmt.Sprint
+`)
+	type Info struct {
+		expr  string
+		typ   string
+		value string
+		mode  ExprMode
+	}
+	infos := []Info{
+		{`1 << 10 + c`, `untyped int`, `1025`, ModeConstant},
+		{`len(arr)`, `int`, `4`, ModeConstant},
+		{`float32(0.49999999)`, `float32`, `0.5`, ModeConstant},
+		{`"foo" + "bar"`, `untyped string`, `"foobar"`, ModeConstant},
+		{`arr[1]`, `int`, ``, ModeVariable},
+		{`m["a"]`, `int`, ``, ModeValue},
+		{`g()`, `int`, ``, ModeValue},
+		{`fmt.Sprint()`, `string`, ``, ModeValue},
+		{`f()`, `()`, ``, ModeNoValue},
+		{`len`, `invalid type`, ``, ModeBuiltin},
+		{`[]int`, `[]int`, ``, ModeTypExpr},
+		{`notExists`, ``, ``, ModeInvalid},
+		{`int(1.2)`, ``, ``, ModeInvalid},
+	}
+	for _, v := range infos {
+		typ := s.TypeOf(v.expr)
+		if (typ == nil && v.typ != "") || (typ != nil && typ.String() != v.typ) {
+			t.Errorf("type of %s got %v, want %q", v.expr, typ, v.typ)
+		}
+		val := s.ConstValue(v.expr)
+		if (val == nil && v.value != "") || (val != nil && val.String() != v.value) {
+			t.Errorf("value of %s got %v, want %q", v.expr, val, v.value)
+		}
+		if mode := s.Mode(v.expr); mode != v.mode {
+			t.Errorf("mode of %s got %s, want %s", v.expr, mode, v.mode)
+		}
+	}
+	if ExprMode(100).String() != "invalid" || ModeTypExpr.String() != "typexpr" {
+		t.Error(`test failed`)
+	}
+
+	// expressions are accepted wherever a value is expected
+	if !s.Conversion("1.0", "int") || s.Conversion("1.2", "int") || !s.Conversion("arr[1]", "float64") ||
+		!s.Representable("1 << 10 + c", "int16") || s.Representable("1 << 10 + c", "int8") || s.Representable("g()", "int") ||
+		!s.Assignment("len(arr)", "int") || s.Assignment("len(arr)", "int8") || s.Assignment("1000", "int8") || !s.Assignment("m[\"a\"]", "int") {
+		t.Error(`test failed`)
+	}
+	// queries do not add errors to the code
+	if !s.Compiles() {
+		t.Error(`test failed`)
+	}
+}
+
+// expressions are evaluated by the Go version and sizes of the Spec
+func TestSpec_eval_config(t *testing.T) {
+	code := `import "unsafe"; var s []int; var p unsafe.Pointer`
+	s := NewSpec(code, WithGoVersion("go1.16"))
+	if s.Mode("min(1, 2)") != ModeInvalid || s.TypeOf("[2]int(s)") != nil || s.TypeOf("(*[2]int)(s)") != nil {
+		t.Error(`test failed`)
+	}
+	if s.Mode("len(s)") != ModeValue || s.ConstValue("unsafe.Sizeof(p)").String() != "8" {
+		t.Error(`test failed`)
+	}
+	s = NewSpec(code)
+	if s.Mode("min(1, 2)") != ModeConstant || s.TypeOf("[2]int(s)").String() != "[2]int" {
+		t.Error(`test failed`)
+	}
+	s = NewSpec(code, WithSizes(types.SizesFor("gc", "386")))
+	if s.ConstValue("unsafe.Sizeof(p)").String() != "4" || s.TypeOf("unsafe.Sizeof(p)") != types.Typ[types.Uintptr] {
+		t.Error(`test failed`)
+	}
+	// the types are still the ones of the Spec
+	s = NewSpec(`type T int; x := T(1)`, WithGoVersion("go1.16"))
+	if s.TypeOf("x + 1") != s.GetType("T") || s.Mode("x") != ModeVariable {
+		t.Error(`test failed`)
+	}
+}
+
+// expressions are checked in a package of their own, whose types are mapped to the ones of the Spec
+func TestSpec_eval_scratch(t *testing.T) {
+	s := NewSpec(`type T struct{ n int }; type G[E any] []E; type L struct{ t T }; l := L{}`, WithSearchKind(SearchAll))
+	if s.TypeOf("l") != s.GetType("L") || s.TypeOf("l.t") != s.GetType("T") ||
+		!types.Identical(s.TypeOf("&l"), types.NewPointer(s.GetType("L"))) ||
+		!types.Identical(s.TypeOf("struct{ n int }{}"), s.GetType("T").Underlying()) ||
+		!types.Identical(s.TypeOf("G[T]{}").Underlying(), types.NewSlice(s.GetType("T"))) {
+		t.Error(`test failed`)
+	}
+	if !s.Assignment("struct{ n int }{}", "T") || !s.Identical("G[int]", "G[int]") || s.Identical("G[int]", "G[T]") {
+		t.Error(`test failed`)
+	}
+	// the scopes of a function literal are not added to the Spec
+	if s.Mode("func(q int) int { return q }") != ModeValue {
+		t.Error(`test failed`)
+	}
+	if len(s.LookupAll("q")) != 0 || s.GetTypeObject("q") != nil {
+		t.Error(`test failed`)
+	}
+	s.SearchKind = SearchAllStrict
+	if o, err := s.LookupStrict("q"); o != nil || err != nil {
+		t.Error(`test failed`)
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/AlaxLee/go-spec-util
 
-go 1.14
+go 1.22
 
 require (
 	github.com/AlaxLee/easyregexp v1.0.1
@@ -1,9 +1,13 @@
 package gospec
 
 import (
+	"fmt"
 	"go/ast"
 	"go/constant"
+	"go/token"
 	"go/types"
+	"runtime"
+	"sync"
 	_ "unsafe"
 )
 
@@ -16,9 +20,6 @@ func _representable(checker *types.Checker, x *operand, typ *types.Basic)
 //go:linkname _conversion go/types.(*Checker).conversion
 func _conversion(checker *types.Checker, x *operand, T types.Type)
 
-//go:linkname isNamed go/types.isNamed
-func isNamed(typ types.Type) bool
-
 //must be kept in sync with operand in src/go/types/operand.go
 type operand struct {
 	mode operandMode
@@ -31,6 +32,144 @@ type operandMode byte
 
 type builtinId int
 
+const invalid operandMode = 0
+
 const constant_ operandMode = 4
 
 const value operandMode = 7
+
+var (
+	hackOnce sync.Once
+	hackErr  error
+)
+
+// checkHack runs known-answer queries through _assignment, _conversion and _representable once,
+// it returns an error wrapping ErrIncompatibleToolchain if any answer is wrong,
+// that is operand no longer matches the one of the running toolchain.
+func checkHack() error {
+	hackOnce.Do(func() {
+		if err := selfTestHack(); err != nil {
+			hackErr = fmt.Errorf("%w: go/types of %s: %v", ErrIncompatibleToolchain, runtime.Version(), err)
+		}
+	})
+	return hackErr
+}
+
+func selfTestHack() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	checker := types.NewChecker(&types.Config{Error: func(error) {}}, token.NewFileSet(), types.NewPackage("hack", "hack"), nil)
+	newConst := func(typ types.BasicKind, val constant.Value) *operand {
+		return &operand{mode: constant_, typ: types.Typ[typ], val: val}
+	}
+	is := func(x *operand, mode operandMode, typ types.BasicKind, val string) bool {
+		if x.mode != mode || mode == invalid {
+			return x.mode == mode
+		}
+		return x.typ == types.Typ[typ] && (x.val == nil && val == "" || x.val != nil && x.val.ExactString() == val)
+	}
+
+	x := &operand{mode: value, typ: types.Typ[types.Int]}
+	_assignment(checker, x, types.Typ[types.Int], "")
+	if !is(x, value, types.Int, "") {
+		return fmt.Errorf("int should be assignable to int")
+	}
+	x = &operand{mode: value, typ: types.Typ[types.Int]}
+	_assignment(checker, x, types.Typ[types.Int64], "")
+	if !is(x, invalid, 0, "") {
+		return fmt.Errorf("int should not be assignable to int64")
+	}
+	x = newConst(types.UntypedInt, constant.MakeInt64(1))
+	_assignment(checker, x, types.Typ[types.Int8], "")
+	if !is(x, constant_, types.Int8, "1") {
+		return fmt.Errorf("1 should be assignable to int8")
+	}
+
+	x = newConst(types.UntypedFloat, constant.MakeFloat64(0.49999999))
+	_conversion(checker, x, types.Typ[types.Float32])
+	if !is(x, constant_, types.Float32, "1/2") {
+		return fmt.Errorf("float32(0.49999999) should be 0.5")
+	}
+	x = &operand{mode: value, typ: types.Typ[types.String]}
+	_conversion(checker, x, types.Typ[types.Int])
+	if !is(x, invalid, 0, "") {
+		return fmt.Errorf("string should not be convertible to int")
+	}
+
+	x = newConst(types.UntypedInt, constant.MakeInt64(300))
+	_representable(checker, x, types.Typ[types.Int8])
+	if !is(x, invalid, 0, "") {
+		return fmt.Errorf("300 should not be representable by int8")
+	}
+	x = newConst(types.UntypedFloat, constant.MakeFloat64(42))
+	_representable(checker, x, types.Typ[types.Uint8])
+	if !is(x, constant_, types.UntypedFloat, "42") {
+		return fmt.Errorf("42.0 should be representable by uint8")
+	}
+	return nil
+}
+
+// linkAssignment reports whether x is assignable to T by go/types.(*Checker).assignment.
+func linkAssignment(checker *types.Checker, x *operand, T types.Type) (bool, error) {
+	if err := checkHack(); err != nil {
+		return false, err
+	}
+	shift := isShiftOperand(x)
+	_assignment(checker, x, T, "")
+	if shift && x.mode != invalid {
+		return shiftTypeOK(x.typ), nil
+	}
+	return x.mode != invalid, nil
+}
+
+// isShiftOperand reports whether x is a non-constant untyped integer or rune, which is the operand of a shift,
+// such as 1 of 1 << s. go/types checks the type it is given when the type of the shift expression is updated,
+// which the operands of the linked functions have not.
+func isShiftOperand(x *operand) bool {
+	b, ok := x.typ.(*types.Basic)
+	return ok && x.mode != constant_ && (b.Kind() == types.UntypedInt || b.Kind() == types.UntypedRune)
+}
+
+// shiftTypeOK reports whether the operand of a shift can be given the type t,
+// an interface which is not a type parameter gives it the default type.
+func shiftTypeOK(t types.Type) bool {
+	if isNonTypeParamInterface(t) {
+		return true
+	}
+	return underIs(t, func(u types.Type) bool {
+		return u != nil && IsInteger(u)
+	})
+}
+
+// linkConversion reports whether x can be converted to T by go/types.(*Checker).conversion,
+// and returns the constant result of the conversion, or nil.
+func linkConversion(checker *types.Checker, x *operand, T types.Type) (constant.Value, bool, error) {
+	if err := checkHack(); err != nil {
+		return nil, false, err
+	}
+	shift := isShiftOperand(x)
+	_conversion(checker, x, T)
+	if shift && x.mode != invalid {
+		return nil, shiftTypeOK(x.typ), nil
+	}
+	if x.mode != constant_ {
+		return nil, x.mode != invalid, nil
+	}
+	return x.val, true, nil
+}
+
+// linkRepresentable reports whether the constant x is representable by typ by go/types.(*Checker).representable,
+// and returns the rounded value.
+func linkRepresentable(checker *types.Checker, x *operand, typ *types.Basic) (constant.Value, bool, error) {
+	if err := checkHack(); err != nil {
+		return nil, false, err
+	}
+	_representable(checker, x, typ)
+	if x.mode == invalid {
+		return nil, false, nil
+	}
+	return x.val, true, nil
+}
@@ -0,0 +1,27 @@
+package gospec
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"testing"
+)
+
+func Test_checkHack(t *testing.T) {
+	if err := checkHack(); err != nil {
+		t.Fatal(err)
+	}
+
+	// once the self-test fails, the linked functions are not called any more
+	s := NewSpec("")
+	saved := hackErr
+	defer func() { hackErr = saved }()
+	hackErr = fmt.Errorf("%w: test", ErrIncompatibleToolchain)
+	x := &operand{mode: value, typ: types.Typ[types.Int]}
+	if ok, err := linkAssignment(s.checker, x, types.Typ[types.Int]); ok || !errors.Is(err, ErrIncompatibleToolchain) {
+		t.Error(`test failed`)
+	}
+	if _, ok, err := linkConversion(s.checker, x, types.Typ[types.Int]); ok || !errors.Is(err, ErrIncompatibleToolchain) {
+		t.Error(`test failed`)
+	}
+}
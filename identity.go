@@ -7,7 +7,21 @@ import (
 func (s *Spec) Identical(v, t string) bool {
 	V := s.MustGetValidType(v)
 	T := s.MustGetValidType(t)
-	return types.Identical(V, T)
+	return mustAnswer(s.oracle.Identical(s, V, T))
+}
+
+// IdenticalE is like Identical, but returns an error instead of panicking,
+// such as a *NotFoundError if v or t is not found.
+func (s *Spec) IdenticalE(v, t string) (bool, error) {
+	V, err := s.getType(v)
+	if err != nil {
+		return false, err
+	}
+	T, err := s.getType(t)
+	if err != nil {
+		return false, err
+	}
+	return s.oracle.Identical(s, V, T)
 }
 
 // Identical(v, t types.Type) bool
@@ -15,6 +29,8 @@ func (s *Spec) Identical(v, t string) bool {
 // Identical(v, t types.Object) bool
 // or
 // Identical((code, v, t string) bool
+//
+// IdenticalTypes, IdenticalObjects or IdenticalIn check their arguments at compile time.
 func Identical(a ...interface{}) bool {
 	switch len(a) {
 	case 2:
@@ -29,9 +45,9 @@ func Identical(a ...interface{}) bool {
 		isAllObject := okV2 && okT2
 
 		if isAllType {
-			return types.Identical(v1, t1)
+			return IdenticalTypes(v1, t1)
 		} else if isAllObject {
-			return types.Identical(v2.Type(), t2.Type())
+			return IdenticalObjects(v2, t2)
 		} else {
 			panic("args must all types.Type or all types.Object")
 		}
@@ -43,8 +59,7 @@ func Identical(a ...interface{}) bool {
 		if !ok1 || !ok2 || !ok3 {
 			panic("args must all string")
 		}
-		s := NewSpec(code)
-		return s.Identical(v, t)
+		return IdenticalIn(code, v, t)
 	default:
 		panic("unexpect")
 	}
@@ -52,6 +67,31 @@ func Identical(a ...interface{}) bool {
 	return true
 }
 
+// IdenticalTypes reports whether v and t are identical.
+func IdenticalTypes(v, t types.Type) bool {
+	return types.Identical(v, t)
+}
+
+// IdenticalObjects reports whether the types of v and t are identical.
+func IdenticalObjects(v, t types.Object) bool {
+	return types.Identical(v.Type(), t.Type())
+}
+
+// IdenticalIn reports whether v and t are identical in code, see Spec.Identical.
+func IdenticalIn(code, v, t string) bool {
+	return cachedSpec(code).Identical(v, t)
+}
+
+// IdenticalInE is like IdenticalIn, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.IdenticalE.
+func IdenticalInE(code, v, t string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.IdenticalE(v, t)
+}
+
 func (s *Spec) IdenticalIgnoreTags(v, t string) bool {
 	V := s.MustGetValidType(v)
 	T := s.MustGetValidType(t)
@@ -63,6 +103,8 @@ func (s *Spec) IdenticalIgnoreTags(v, t string) bool {
 // IdenticalIgnoreTags(v, t types.Object) bool
 // or
 // IdenticalIgnoreTags((code, v, t string) bool
+//
+// IdenticalIgnoreTagsTypes, IdenticalIgnoreTagsObjects or IdenticalIgnoreTagsIn check their arguments at compile time.
 func IdenticalIgnoreTags(a ...interface{}) bool {
 	switch len(a) {
 	case 2:
@@ -77,9 +119,9 @@ func IdenticalIgnoreTags(a ...interface{}) bool {
 		isAllObject := okV2 && okT2
 
 		if isAllType {
-			return types.IdenticalIgnoreTags(v1, t1)
+			return IdenticalIgnoreTagsTypes(v1, t1)
 		} else if isAllObject {
-			return types.IdenticalIgnoreTags(v2.Type(), t2.Type())
+			return IdenticalIgnoreTagsObjects(v2, t2)
 		} else {
 			panic("args must all types.Type or all types.Object")
 		}
@@ -91,11 +133,25 @@ func IdenticalIgnoreTags(a ...interface{}) bool {
 		if !ok1 || !ok2 || !ok3 {
 			panic("args must all string")
 		}
-		s := NewSpec(code)
-		return s.IdenticalIgnoreTags(v, t)
+		return IdenticalIgnoreTagsIn(code, v, t)
 	default:
 		panic("unexpect")
 	}
 
 	return true
 }
+
+// IdenticalIgnoreTagsTypes reports whether v and t are identical ignoring struct tags.
+func IdenticalIgnoreTagsTypes(v, t types.Type) bool {
+	return types.IdenticalIgnoreTags(v, t)
+}
+
+// IdenticalIgnoreTagsObjects reports whether the types of v and t are identical ignoring struct tags.
+func IdenticalIgnoreTagsObjects(v, t types.Object) bool {
+	return types.IdenticalIgnoreTags(v.Type(), t.Type())
+}
+
+// IdenticalIgnoreTagsIn reports whether v and t are identical ignoring struct tags in code, see Spec.IdenticalIgnoreTags.
+func IdenticalIgnoreTagsIn(code, v, t string) bool {
+	return cachedSpec(code).IdenticalIgnoreTags(v, t)
+}
@@ -285,3 +285,22 @@ type V = struct {
 		t.Error(`test rule failed`)
 	}
 }
+
+// func IdenticalTypes(v, t types.Type) bool
+func TestIdenticalTypes(t *testing.T) {
+	code := "type T int; type A = T; type U struct{ f int `json:\"f\"` }; type V = struct{ f int }"
+	s := NewSpec(code)
+	if !IdenticalTypes(s.GetType("T"), s.GetType("A")) || IdenticalTypes(s.GetType("T"), s.GetType("int")) {
+		t.Error(`test failed`)
+	}
+	if !IdenticalObjects(s.GetTypeObject("T"), s.GetTypeObject("A")) || !IdenticalIn(code, "T", "A") {
+		t.Error(`test failed`)
+	}
+	U, V := s.GetType("U").Underlying(), s.GetType("V")
+	if IdenticalTypes(U, V) || !IdenticalIgnoreTagsTypes(U, V) || !IdenticalIgnoreTagsIn(code, "V", "struct{ f int `json:\"g\"` }") {
+		t.Error(`test failed`)
+	}
+	if !IdenticalIgnoreTagsObjects(s.GetTypeObject("T"), s.GetTypeObject("A")) {
+		t.Error(`test failed`)
+	}
+}
@@ -6,7 +6,21 @@ func (s *Spec) Implements(v, t string) bool {
 	V := s.MustGetValidType(v)
 	T := s.MustGetValidType(t)
 
-	return implements(V, T)
+	return mustAnswer(s.oracle.Implements(s, V, T))
+}
+
+// ImplementsE is like Implements, but returns an error instead of panicking,
+// such as a *NotFoundError if v or t is not found.
+func (s *Spec) ImplementsE(v, t string) (bool, error) {
+	V, err := s.getType(v)
+	if err != nil {
+		return false, err
+	}
+	T, err := s.getType(t)
+	if err != nil {
+		return false, err
+	}
+	return s.oracle.Implements(s, V, T)
 }
 
 // Implements(v, t types.Type) bool
@@ -14,6 +28,8 @@ func (s *Spec) Implements(v, t string) bool {
 // Implements(v, t types.Object) bool
 // or
 // Implements((code, v, t string) bool
+//
+// ImplementsTypes, ImplementsObjects or ImplementsIn check their arguments at compile time.
 func Implements(a ...interface{}) bool {
 	switch len(a) {
 	case 2:
@@ -28,9 +44,9 @@ func Implements(a ...interface{}) bool {
 		isAllObject := okV2 && okT2
 
 		if isAllType {
-			return implements(v1, t1)
+			return ImplementsTypes(v1, t1)
 		} else if isAllObject {
-			return implements(v2.Type(), t2.Type())
+			return ImplementsObjects(v2, t2)
 		} else {
 			panic("args must all types.Type or all types.Object")
 		}
@@ -42,8 +58,7 @@ func Implements(a ...interface{}) bool {
 		if !ok1 || !ok2 || !ok3 {
 			panic("args must all string")
 		}
-		s := NewSpec(code)
-		return s.Implements(v, t)
+		return ImplementsIn(code, v, t)
 	default:
 		panic("unexpect")
 	}
@@ -51,6 +66,31 @@ func Implements(a ...interface{}) bool {
 	return true
 }
 
+// ImplementsTypes reports whether v implements the interface t, it is false if t is not an interface.
+func ImplementsTypes(v, t types.Type) bool {
+	return implements(v, t)
+}
+
+// ImplementsObjects reports whether the type of v implements the type of t, see ImplementsTypes.
+func ImplementsObjects(v, t types.Object) bool {
+	return implements(v.Type(), t.Type())
+}
+
+// ImplementsIn reports whether v implements t in code, see Spec.Implements.
+func ImplementsIn(code, v, t string) bool {
+	return cachedSpec(code).Implements(v, t)
+}
+
+// ImplementsInE is like ImplementsIn, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.ImplementsE.
+func ImplementsInE(code, v, t string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.ImplementsE(v, t)
+}
+
 // logic like src/go/types/operand.go line 254
 func implements(v, t types.Type) bool {
 	tu := t.Underlying()
@@ -54,3 +54,19 @@ var x V
 		t.Errorf("test failed")
 	}
 }
+
+// func ImplementsTypes(v, t types.Type) bool
+func TestImplementsTypes(t *testing.T) {
+	code := `import "fmt"; type T int; func (T) String() string { return "" }; type U int; var _ fmt.Stringer`
+	s := NewSpec(code)
+	if !ImplementsTypes(s.GetType("T"), s.GetType("fmt.Stringer")) || ImplementsTypes(s.GetType("U"), s.GetType("fmt.Stringer")) {
+		t.Error(`test failed`)
+	}
+	// t is not an interface
+	if ImplementsTypes(s.GetType("T"), s.GetType("U")) {
+		t.Error(`test failed`)
+	}
+	if !ImplementsObjects(s.GetTypeObject("T"), s.GetTypeObject("fmt.Stringer")) || ImplementsIn(code, "U", "fmt.Stringer") {
+		t.Error(`test failed`)
+	}
+}
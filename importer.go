@@ -0,0 +1,145 @@
+package gospec
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sync"
+)
+
+// sourceImporter imports packages by type-checking their source code, found by go/build.
+// Unlike the "source" importer of go/importer, module packages are resolved from dir
+// (go/build asks the go command from the directory of its context), so the packages
+// of the module dir belongs to can be imported.
+type sourceImporter struct {
+	ctxt     build.Context
+	fset     *token.FileSet
+	packages map[string]*types.Package
+}
+
+func newSourceImporter(dir string) *sourceImporter {
+	ctxt := build.Default
+	ctxt.Dir = dir
+	ctxt.CgoEnabled = false // cgo files need the cgo tool, packages of std fall back to their pure Go files
+	return &sourceImporter{
+		ctxt:     ctxt,
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*types.Package),
+	}
+}
+
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, imp.ctxt.Dir, 0)
+}
+
+func (imp *sourceImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if dir == "" {
+		dir = imp.ctxt.Dir
+	}
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bp, err := imp.ctxt.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, ok := imp.packages[bp.ImportPath]; ok {
+		if pkg == nil {
+			return nil, errors.New("import cycle not allowed")
+		}
+		return pkg, nil
+	}
+	imp.packages[bp.ImportPath] = nil
+
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(imp.fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			delete(imp.packages, bp.ImportPath)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	c := &types.Config{Importer: imp}
+	pkg, err := c.Check(bp.ImportPath, imp.fset, files, nil)
+	if err != nil {
+		delete(imp.packages, bp.ImportPath)
+		return nil, fmt.Errorf("type-checking package %q failed (%v)", bp.ImportPath, err)
+	}
+	imp.packages[bp.ImportPath] = pkg
+	return pkg, nil
+}
+
+// ImporterMode selects how a Spec imports packages, see GetImporter.
+type ImporterMode int
+
+const (
+	// ImportAuto imports packages like ImportDefault, and falls back to ImportSource when it fails,
+	// as on toolchains which do not ship export data for the standard library.
+	ImportAuto ImporterMode = iota
+	// ImportDefault imports packages with go/importer.Default, from the export data of the compiler.
+	ImportDefault
+	// ImportSource imports packages by type-checking their source code.
+	ImportSource
+)
+
+var (
+	defaultImporter = &sharedImporter{imp: importer.Default().(types.ImporterFrom)}
+	srcImporter     = &sharedImporter{imp: newSourceImporter("")}
+	autoImporter    = &sharedImporter{imp: &fallbackImporter{primary: defaultImporter, fallback: srcImporter}}
+)
+
+// GetImporter returns the importer for mode. It is shared by all Specs,
+// so a package is imported only once, and its types are identical in all Specs.
+func GetImporter(mode ImporterMode) types.Importer {
+	switch mode {
+	case ImportAuto:
+		return autoImporter
+	case ImportDefault:
+		return defaultImporter
+	case ImportSource:
+		return srcImporter
+	default:
+		panic("unexpect")
+	}
+}
+
+// sharedImporter makes imp safe to be shared by Specs.
+type sharedImporter struct {
+	mu  sync.Mutex
+	imp types.ImporterFrom
+}
+
+func (imp *sharedImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *sharedImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+	return imp.imp.ImportFrom(path, dir, mode)
+}
+
+// fallbackImporter imports a package by fallback if primary fails to import it.
+type fallbackImporter struct {
+	primary  types.ImporterFrom
+	fallback types.ImporterFrom
+}
+
+func (imp *fallbackImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *fallbackImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	pkg, err := imp.primary.ImportFrom(path, dir, mode)
+	if err != nil {
+		pkg, err = imp.fallback.ImportFrom(path, dir, mode)
+	}
+	return pkg, err
+}
@@ -0,0 +1,73 @@
+package gospec
+
+import (
+	"errors"
+	"go/types"
+	"testing"
+)
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func (f importerFunc) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	return f(path)
+}
+
+// func GetImporter(mode ImporterMode) types.Importer
+func TestGetImporter(t *testing.T) {
+	for _, mode := range []ImporterMode{ImportAuto, ImportDefault, ImportSource} {
+		imp := GetImporter(mode)
+		if imp != GetImporter(mode) {
+			t.Error(`test failed`)
+		}
+		// the importers are shared by Specs, so are the imported packages
+		code := `import "fmt"; var x fmt.Stringer`
+		s1 := NewSpec(code, WithImporter(imp))
+		s2 := NewSpec(code, WithImporter(imp))
+		if !Identical(s1.GetType("x"), s2.GetType("x")) {
+			t.Error(`test failed`)
+		}
+	}
+
+	s := NewSpec(`import "go/types"; var x types.Type`, WithImporter(GetImporter(ImportSource)))
+	if s.GetType("x").String() != "go/types.Type" {
+		t.Error(`test failed`)
+	}
+}
+
+// func WithImporter(imp types.Importer) Option
+func TestWithImporter(t *testing.T) {
+	imp := importerFunc(func(path string) (*types.Package, error) {
+		pkg := types.NewPackage(path, "fake")
+		pkg.Scope().Insert(types.NewTypeName(0, pkg, "T", types.Typ[types.Int]))
+		pkg.MarkComplete()
+		return pkg, nil
+	})
+	s, err := NewSpecE(`import "x/fake"; var x fake.T`, WithImporter(imp))
+	if err != nil || s.GetType("x").String() != "int" {
+		t.Error(`test failed`)
+	}
+
+	failed := importerFunc(func(path string) (*types.Package, error) {
+		return nil, errors.New("not found")
+	})
+	if _, err := NewSpecE(`import "fmt"`, WithImporter(failed)); err == nil {
+		t.Error(`test failed`)
+	}
+}
+
+func TestFallbackImporter(t *testing.T) {
+	failed := importerFunc(func(path string) (*types.Package, error) {
+		return nil, errors.New("not found")
+	})
+	imp := &fallbackImporter{primary: failed, fallback: srcImporter}
+	pkg, err := imp.Import("fmt")
+	if err != nil || pkg.Path() != "fmt" {
+		t.Error(`test failed`)
+	}
+	imp = &fallbackImporter{primary: failed, fallback: failed}
+	if _, err := imp.Import("fmt"); err == nil {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,73 @@
+package gospec
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path"
+	"path/filepath"
+
+	easyregexp "github.com/AlaxLee/easyregexp"
+)
+
+// LoadSpec type-checks the Go package in the directory dir, with the files go/build selects for it
+// (test files and cgo files are not loaded). Imports are type-checked from their source code,
+// including the packages of the module dir belongs to. Like NewSpecE, a Spec is returned with a *CheckError if the package
+// fails to type-check. The option WithPackageName is ignored, WithPackagePath overrides the import path
+// found for dir.
+func LoadSpec(dir string, opts ...Option) (*Spec, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("load package in %s failed: %w", dir, err)
+	}
+
+	sources := make([]*sourceFile, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		filename := filepath.Join(dir, name)
+		code, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, &sourceFile{name: filename, code: string(code), orig: string(code)})
+	}
+	cfg := newConfig(opts)
+	if cfg.importer == nil {
+		cfg.importer = newSourceImporter(dir)
+	}
+	packagePath := cfg.packagePath
+	if packagePath == "" {
+		packagePath = importPathOfDir(bp, dir)
+	}
+	return newSpec(packagePath, bp.Name, sources, cfg)
+}
+
+// importPathOfDir returns the import path of the package bp in dir.
+// In module mode go/build does not know it, so it is made of the module path in go.mod.
+func importPathOfDir(bp *build.Package, dir string) string {
+	if bp.ImportPath != "." {
+		return bp.ImportPath
+	}
+	for root := dir; ; root = filepath.Dir(root) {
+		if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			catches := easyregexp.Catch(`(?m)^module\s+"?([^"\s]+)"?`, string(data))
+			if len(catches) == 0 {
+				break
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				break
+			}
+			return path.Join(catches[0], filepath.ToSlash(rel))
+		} else if !os.IsNotExist(err) {
+			break
+		}
+		if filepath.Dir(root) == root {
+			break
+		}
+	}
+	return bp.Name
+}
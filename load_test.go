@@ -0,0 +1,74 @@
+package gospec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, code := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// func LoadSpec(dir string) (*Spec, error)
+func TestLoadSpec(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.14\n",
+		"a/a.go": `package a
+
+import "fmt"
+
+type T struct{ x int }
+
+func (T) String() string { return fmt.Sprint("T") }
+`,
+		"b/b.go": `package b
+
+import (
+	"fmt"
+
+	"example.com/m/a"
+)
+
+type T struct{ x int }
+
+type S = a.T
+
+const C = 1 << 10
+
+var V fmt.Stringer = a.T{}
+`,
+		"b/b_test.go": `package b
+
+var NotLoaded int
+`,
+	})
+
+	s, err := LoadSpec(filepath.Join(root, "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.pkg.Path() != "example.com/m/b" || s.pkg.Name() != "b" || s.GetTypeObject("NotLoaded") != nil {
+		t.Error(`test failed`)
+	}
+	if s.Identical("T", "S") || !s.Identical("S", "example.com/m/a.T") ||
+		s.Assignment("T", "V") || !s.Assignment("S", "V") ||
+		s.Conversion("T", "S") || !s.Conversion("C", "int16") ||
+		!s.Comparable("T") || !s.Implements("S", "V") ||
+		!s.Representable("C", "int16") || s.Representable("C", "int8") {
+		t.Error(`test failed`)
+	}
+
+	if _, err := LoadSpec(filepath.Join(root, "c")); err == nil {
+		t.Error(`test failed`)
+	}
+}
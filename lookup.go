@@ -0,0 +1,189 @@
+package gospec
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// lookupPath finds the object of a path v, made of names separated by "." or "/":
+//
+//	fmt.Stringer           a name of an imported package
+//	example.com/m/a.T      a name of a package by its import path, see Package
+//	T.Method, T.field      a method or field of a type, or of the type of a variable
+//	main.b, main/c         a local in a function (or method, such as T.Method/x)
+//
+// The first name is looked up in the package scope, the file scopes and then the universe scope.
+// The package of s qualifies a name, such as main.b, only if it is not found as a local.
+// It returns nil if v is not a path, or it is not found.
+func (s *Spec) lookupPath(v string) types.Object {
+	if !strings.ContainsAny(v, "./") {
+		return nil
+	}
+	if o := s.lookupQualified(v, false); o != nil {
+		return o
+	}
+	if o := s.lookupMembers(v); o != nil {
+		return o
+	}
+	return s.lookupQualified(v, true)
+}
+
+// lookupMembers finds the names of the path v one in another, see lookupPath.
+func (s *Spec) lookupMembers(v string) types.Object {
+	names := strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '/' })
+	if len(names) < 2 {
+		return nil
+	}
+	o := s.lookupFirstName(names[0])
+	for _, name := range names[1:] {
+		if o == nil {
+			return nil
+		}
+		o = lookupMember(o, name)
+	}
+	return o
+}
+
+// lookupFirstName finds name in the package scope, the file scopes and then the universe scope.
+func (s *Spec) lookupFirstName(name string) types.Object {
+	if o := s.lookupPackage(name); o != nil {
+		return o
+	}
+	for i := 0; i < s.pkg.Scope().NumChildren(); i++ {
+		if o := s.pkg.Scope().Child(i).Lookup(name); o != nil {
+			return o
+		}
+	}
+	return s.lookupUniverse(name)
+}
+
+// lookupMember finds name in o, that is an imported package, a function or something has a type.
+func lookupMember(o types.Object, name string) types.Object {
+	switch o := o.(type) {
+	case *types.PkgName:
+		return o.Imported().Scope().Lookup(name)
+	case *types.Func:
+		if o.Scope() == nil { // function from export data
+			return nil
+		}
+		return lookupByLevel(o.Scope(), name)
+	case *types.TypeName, *types.Var, *types.Const:
+		member, _, _ := types.LookupFieldOrMethod(o.Type(), true, o.Pkg(), name)
+		return member
+	default:
+		return nil
+	}
+}
+
+// lookupByLevel finds name in scope and its children level by level,
+// so a name declared in an outer block is found before the ones in its inner blocks.
+func lookupByLevel(scope *types.Scope, name string) types.Object {
+	scopes := []*types.Scope{scope}
+	for len(scopes) > 0 {
+		var children []*types.Scope
+		for _, sc := range scopes {
+			if o := sc.Lookup(name); o != nil {
+				return o
+			}
+			for i := 0; i < sc.NumChildren(); i++ {
+				children = append(children, sc.Child(i))
+			}
+		}
+		scopes = children
+	}
+	return nil
+}
+
+// LookupStrict finds the object named v like SearchAll, but returns an *AmbiguityError
+// listing all candidates if v is declared in several scopes of the code (package, file and local scopes).
+// Paths, such as "main.b", are found like GetTypeObject. It returns nil and no error if v is not found.
+func (s *Spec) LookupStrict(v string) (types.Object, error) {
+	if o := s.lookupPath(v); o != nil {
+		return o, nil
+	}
+	var candidates []ObjectInfo
+	for _, info := range s.LookupAll(v) {
+		if info.Scope != types.Universe {
+			candidates = append(candidates, info)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return s.lookupUniverse(v), nil
+	case 1:
+		return candidates[0].Object, nil
+	default:
+		return nil, &AmbiguityError{Name: v, Candidates: candidates}
+	}
+}
+
+// LookupAt finds the object named name as seen at line and column col of the code
+// (the code of the first file for NewSpecFromFiles), following the scope rules of Go:
+// the innermost declaration wins, and a local is only seen after its declaration.
+// It returns nil if name is not visible there.
+func (s *Spec) LookupAt(name string, line, col int) types.Object {
+	pos := s.pos(s.sources[0], line, col)
+	if !pos.IsValid() {
+		return nil
+	}
+	_, o := s.pkg.Scope().Innermost(pos).LookupParent(name, pos)
+	return o
+}
+
+// LookupInFunc finds the object named name as seen at the end of the line of the function fn,
+// such as "main" or "T.Method", see LookupAt. It returns an error if there is no such function,
+// or line is out of it.
+func (s *Spec) LookupInFunc(fn, name string, line int) (types.Object, error) {
+	o := s.lookupPath(fn)
+	if o == nil {
+		o = s.pkg.Scope().Lookup(fn)
+	}
+	// the functions of imported packages, such as "strings.ToUpper" imported from source, are not in the code
+	f, ok := o.(*types.Func)
+	if !ok || f.Scope() == nil || f.Pkg() != s.pkg {
+		return nil, fmt.Errorf("no function <%s> in code <%s>", fn, s.code)
+	}
+	scope := f.Scope()
+	start, end := s.fset.Position(scope.Pos()), s.fset.Position(scope.End())
+	src := s.source(start.Filename)
+	if src == nil {
+		return nil, fmt.Errorf("no function <%s> in code <%s>", fn, s.code)
+	}
+	first, last := src.originalPosition(start).Line, src.originalPosition(end).Line
+	if line < first || line > last {
+		return nil, fmt.Errorf("line %d is out of function <%s>", line, fn)
+	}
+	pos := scope.End() - 1 // the closing brace
+	if line < last {
+		pos = s.pos(src, line+1, 1) - 1 // the end of line
+	}
+	_, o = scope.Innermost(pos).LookupParent(name, pos)
+	return o, nil
+}
+
+// source returns the source file named filename.
+func (s *Spec) source(filename string) *sourceFile {
+	for _, src := range s.sources {
+		if src.name == filename {
+			return src
+		}
+	}
+	return nil
+}
+
+// pos returns the position of line and column col of the original code of src,
+// or token.NoPos if there is no such position.
+func (s *Spec) pos(src *sourceFile, line, col int) token.Pos {
+	offset, ok := src.offsetOf(line, col)
+	if !ok {
+		return token.NoPos
+	}
+	for _, f := range s.files {
+		if tf := s.fset.File(f.Pos()); tf.Name() == src.name {
+			return token.Pos(tf.Base() + offset)
+		}
+	}
+	return token.NoPos
+}
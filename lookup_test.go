@@ -0,0 +1,196 @@
+package gospec
+
+import (
+	"fmt"
+	"testing"
+)
+
+// func (s *Spec) lookupPath(v string) types.Object
+func TestSpec_lookupPath(t *testing.T) {
+	s := NewSpec(`
+package haha
+import (
+	"fmt"
+	str "strings"
+)
+type T struct {
+	x int
+	E
+}
+type E struct{ Y string }
+func (T) M() {}
+func (*T) PM() {
+	var z = 1
+	_ = z
+}
+var t T
+func main() {
+	var b = 2.0
+	type c struct {
+		d string
+	}
+	{
+		b := "inner"
+		_ = b
+	}
+	fmt.Println(b, str.ToUpper(""))
+}
+func other() {
+	var b int
+	_ = b
+}`)
+	for v, want := range map[string]string{
+		"fmt.Stringer":   "type fmt.Stringer interface{String() string}",
+		"str.ToUpper":    "func strings.ToUpper(s string) string",
+		"T.x":            "field x int",
+		"T.Y":            "field Y string",
+		"T.M":            "func (haha.T).M()",
+		"T.PM":           "func (*haha.T).PM()",
+		"T.PM/z":         "var z int",
+		"t.x":            "field x int",
+		"main.b":         "var b float64",
+		"main/b":         "var b float64",
+		"other.b":        "var b int",
+		"main.c":         "type c struct{d string}",
+		"main.c.d":       "field d string",
+		"haha.T":         "type haha.T struct{x int; haha.E}",
+		"error.Error":    "func (error).Error() string",
+		"fmt.NotExists":  "",
+		"main.notExists": "",
+		"T.notExists":    "",
+		"notExists.x":    "",
+		"[]fmt.Stringer": "",
+	} {
+		o := s.GetTypeObject(v)
+		if (o == nil && want != "") || (o != nil && o.String() != want) {
+			t.Errorf("lookup %s got %v, want %q", v, o, want)
+		}
+	}
+
+	// paths are found whatever SearchKind is
+	s.SearchKind = SearchOnlyPackage
+	if s.GetTypeObject("main.c") == nil || s.GetTypeObject("c") != nil {
+		t.Error(`test failed`)
+	}
+
+	// a local of the function main hides the name of the package main
+	s = NewSpec("package main\nvar b = 1\nvar d = 2\nfunc main() { var b = \"s\"; _ = b }")
+	for v, want := range map[string]string{
+		"main.b": "var b string",
+		"main/b": "var b string",
+		"main.d": "var main.d int",
+		"b":      "var main.b int",
+	} {
+		if o := s.GetTypeObject(v); o == nil || o.String() != want {
+			t.Errorf("lookup %s got %v, want %q", v, o, want)
+		}
+	}
+}
+
+// func (s *Spec) LookupStrict(v string) (types.Object, error)
+func TestSpec_LookupStrict(t *testing.T) {
+	s := NewSpec(objectsCode)
+	o, err := s.LookupStrict("x")
+	ambiguityErr, ok := err.(*AmbiguityError)
+	if o != nil || !ok || len(ambiguityErr.Candidates) != 3 ||
+		err.Error() != "<x> is ambiguous, it is declared at 5:2 in T.M, 9:6 in main, 17:6 in other" {
+		t.Errorf("test failed: %v", err)
+	}
+	if o, err := s.LookupStrict("y"); err != nil || o.String() != "var y int" {
+		t.Error(`test failed`)
+	}
+	if o, err := s.LookupStrict("int"); err != nil || o.String() != "type int" {
+		t.Error(`test failed`)
+	}
+	if o, err := s.LookupStrict("main.x"); err != nil || o.String() != "var x float64" {
+		t.Error(`test failed`)
+	}
+	if o, err := s.LookupStrict("notExists"); err != nil || o != nil {
+		t.Error(`test failed`)
+	}
+
+	s.SearchKind = SearchAllStrict
+	if s.GetTypeObject("x") != nil || s.GetTypeObject("y").String() != "var y int" || s.IsInUniverse("x") || !s.IsInUniverse("int") {
+		t.Error(`test failed`)
+	}
+	testGet := func(v string) (r string) {
+		defer func() {
+			r = fmt.Sprint(recover())
+		}()
+		s.MustGetValidType(v)
+		return
+	}
+	if testGet("x") != err.Error() || testGet("notExists") != "find <notExists> in code <"+s.code+"> failed" {
+		t.Error(`test failed`)
+	}
+}
+
+// func (s *Spec) LookupAt(name string, line, col int) types.Object
+// func (s *Spec) LookupInFunc(fn, name string, line int) (types.Object, error)
+func TestSpec_LookupAt(t *testing.T) {
+	s := NewSpec(`
+var x = "package"
+func main() {
+	_ = x
+	x := 1.0
+	{
+		x := 1
+		_ = x
+	}
+	_ = x
+}
+func (T) M() {
+	x := 'm'
+	_ = x
+}
+type T struct{}`)
+	for _, v := range []struct {
+		line, col int
+		want      string
+	}{
+		{4, 6, "var example.x string"},
+		{5, 2, "var example.x string"}, // x is declared at the end of the statement
+		{6, 1, "var x float64"},
+		{8, 7, "var x int"},
+		{10, 6, "var x float64"},
+		{13, 2, "var example.x string"},
+		{14, 6, "var x rune"},
+		{16, 1, "var example.x string"},
+		{100, 1, "<nil>"},
+	} {
+		if got := fmt.Sprint(s.LookupAt("x", v.line, v.col)); got != v.want {
+			t.Errorf("x at %d:%d got %s, want %s", v.line, v.col, got, v.want)
+		}
+	}
+
+	for _, v := range []struct {
+		fn   string
+		line int
+		want string
+	}{
+		{"main", 3, "var example.x string"},
+		{"main", 4, "var example.x string"},
+		{"main", 5, "var x float64"},
+		{"main", 7, "var x int"},
+		{"main", 11, "var x float64"},
+		{"T.M", 13, "var x rune"},
+		{"main", 12, "error"},
+		{"main", 1, "error"},
+		{"T", 13, "error"},
+		{"notExists", 13, "error"},
+	} {
+		o, err := s.LookupInFunc(v.fn, "x", v.line)
+		got := fmt.Sprint(o)
+		if err != nil {
+			got = "error"
+		}
+		if got != v.want {
+			t.Errorf("x in %s at line %d got %s, want %s", v.fn, v.line, got, v.want)
+		}
+	}
+	// a function imported from source has a scope, but not in the code
+	s = NewSpec(`import "strings"; var _ = strings.ToUpper`, WithImporter(GetImporter(ImportSource)))
+	if o, err := s.LookupInFunc("strings.ToUpper", "s", 1); o != nil || err == nil {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,137 @@
+package gospec
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"strings"
+)
+
+// NewSpecFromPackages is like NewSpecFromFiles, but the code is made of several packages held in memory.
+// packages maps import paths to the files of each package, as in NewSpecFromFiles.
+// The Spec is made of the package main, which may import the other packages by their paths,
+// and they may import each other. Objects of other packages are found by "path.Name", such as "a.T".
+// A file without package clause gets the last element of the import path as its package name.
+// The options WithPackageName and WithPackagePath are ignored, the packages are named by their paths.
+func NewSpecFromPackages(main string, packages map[string]map[string]string, opts ...Option) *Spec {
+	s, err := NewSpecFromPackagesE(main, packages, opts...)
+	if err != nil {
+		log.Panic(err)
+	}
+	return s
+}
+
+// NewSpecFromPackagesE is like NewSpecFromPackages, but returns an error instead of panicking, see NewSpecE.
+// Errors of all imported packages held in memory are kept by the Spec as well.
+func NewSpecFromPackagesE(main string, packages map[string]map[string]string, opts ...Option) (*Spec, error) {
+	files, ok := packages[main]
+	if !ok || len(files) == 0 {
+		return nil, fmt.Errorf("no files of package %q", main)
+	}
+	packageName, sources := sourcesOf(files, main, "")
+	cfg := newConfig(opts)
+	imp := newMemImporter(packages, cfg)
+	imp.checked[main] = nil // main can not be imported
+	cfg.importer = imp
+	return newSpec(main, packageName, sources, cfg)
+}
+
+// Package returns the package with the given import path, if it is the package of s,
+// a package imported by s, or a package held in memory and imported by s, and nil otherwise.
+func (s *Spec) Package(path string) *types.Package {
+	if s.pkg.Path() == path {
+		return s.pkg
+	}
+	for _, pkg := range s.pkg.Imports() {
+		if pkg.Path() == path {
+			return pkg
+		}
+	}
+	if imp, ok := s.importer.(*memImporter); ok {
+		return imp.checked[path]
+	}
+	return nil
+}
+
+// lookupQualified finds "path.Name" in the packages of s, see Package.
+// If own is false, the package of s is skipped.
+func (s *Spec) lookupQualified(v string, own bool) types.Object {
+	i := strings.LastIndex(v, ".")
+	if i < 0 {
+		return nil
+	}
+	pkg := s.Package(v[:i])
+	if pkg == nil || pkg == s.pkg && !own {
+		return nil
+	}
+	return pkg.Scope().Lookup(v[i+1:])
+}
+
+// memImporter imports packages from their code held in memory, and the others by fallback.
+type memImporter struct {
+	fset     *token.FileSet
+	packages map[string]map[string]string
+	checked  map[string]*types.Package
+	fallback types.Importer
+	conf     types.Config
+
+	onError func(err error)
+	onCheck func(sources []*sourceFile)
+}
+
+// newMemImporter imports the packages not held in memory by the importer of cfg (GetImporter(ImportAuto) by default),
+// and checks the packages held in memory by the Go version of cfg.
+func newMemImporter(packages map[string]map[string]string, cfg *config) *memImporter {
+	fallback := cfg.importer
+	if fallback == nil {
+		fallback = GetImporter(ImportAuto)
+	}
+	imp := &memImporter{
+		fset:     token.NewFileSet(),
+		packages: packages,
+		checked:  make(map[string]*types.Package),
+		fallback: fallback,
+		onError:  func(err error) {},
+		onCheck:  func(sources []*sourceFile) {},
+	}
+	imp.conf = types.Config{
+		Importer:  imp,
+		Error:     func(err error) { imp.onError(err) },
+		GoVersion: cfg.goVersion,
+		Sizes:     cfg.sizes,
+	}
+	return imp
+}
+
+func (imp *memImporter) Import(path string) (*types.Package, error) {
+	files, ok := imp.packages[path]
+	if !ok {
+		return imp.fallback.Import(path)
+	}
+	if pkg, ok := imp.checked[path]; ok {
+		if pkg == nil {
+			return nil, errors.New("import cycle not allowed")
+		}
+		return pkg, nil
+	}
+	imp.checked[path] = nil
+
+	_, sources := sourcesOf(files, path, "")
+	imp.onCheck(sources)
+	astFiles := make([]*ast.File, 0, len(sources))
+	for _, src := range sources {
+		f, err := parser.ParseFile(imp.fset, src.name, src.code, 0)
+		if err != nil {
+			delete(imp.checked, path)
+			return nil, fmt.Errorf("parse code failed: %w", err)
+		}
+		astFiles = append(astFiles, f)
+	}
+	pkg, _ := imp.conf.Check(path, imp.fset, astFiles, nil)
+	imp.checked[path] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,95 @@
+package gospec
+
+import (
+	"strings"
+	"testing"
+)
+
+// func NewSpecFromPackages(main string, packages map[string]map[string]string) *Spec
+func TestNewSpecFromPackages(t *testing.T) {
+	s := NewSpecFromPackages("b", map[string]map[string]string{
+		"a": {"a.go": `
+type T struct{ x int }
+type E struct{ X int }
+type I interface{ m() }
+type J interface{ M() }
+type V struct{}
+func (V) m() {}
+`},
+		"b": {"b.go": `
+import "a"
+type T struct{ x int }
+type E struct{ X int }
+type I interface{ m() }
+type J interface{ M() }
+type U = a.T
+var v a.V
+`},
+	})
+
+	// A defined type is always different from any other type.
+	if s.Identical("a.T", "b.T") || s.Identical("a.E", "E") || !s.Identical("a.T", "U") {
+		t.Error(`test failed`)
+	}
+	// Non-exported field names from different packages are always different.
+	if Identical(s.GetUnderlyingType("a.T"), s.GetUnderlyingType("b.T")) ||
+		!Identical(s.GetUnderlyingType("a.E"), s.GetUnderlyingType("E")) {
+		t.Error(`test failed`)
+	}
+	// Non-exported method names from different packages are always different.
+	if Identical(s.GetUnderlyingType("a.I"), s.GetUnderlyingType("I")) ||
+		!Identical(s.GetUnderlyingType("a.J"), s.GetUnderlyingType("J")) {
+		t.Error(`test failed`)
+	}
+	if !s.Implements("v", "a.I") || s.Implements("v", "I") {
+		t.Error(`test failed`)
+	}
+
+	if s.Package("a").Path() != "a" || s.Package("b") != s.pkg || s.Package("c") != nil {
+		t.Error(`test failed`)
+	}
+}
+
+// func NewSpecFromPackagesE(main string, packages map[string]map[string]string) (*Spec, error)
+func TestNewSpecFromPackagesE(t *testing.T) {
+	if _, err := NewSpecFromPackagesE("c", map[string]map[string]string{"a": {"a.go": ``}}); err == nil {
+		t.Error(`test failed`)
+	}
+
+	// errors of imported packages are kept
+	s, err := NewSpecFromPackagesE("example.com/b", map[string]map[string]string{
+		"example.com/a": {"a.go": `var X int = "x"`},
+		"example.com/b": {"b.go": `import "example.com/a"; var Y = a.X`},
+	})
+	if _, ok := err.(*CheckError); !ok {
+		t.Fatal(`test failed`)
+	}
+	ds := s.Diagnostics()
+	if len(ds) != 1 || ds[0].Pos.Filename != "example.com/a/a.go" || ds[0].Pos.Line != 1 || s.GetType("Y").String() != "int" {
+		t.Error(`test failed`)
+	}
+
+	// import cycle
+	_, err = NewSpecFromPackagesE("a", map[string]map[string]string{
+		"a": {"a.go": `import _ "b"`},
+		"b": {"b.go": `import _ "a"`},
+	})
+	if err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Error(`test failed`)
+	}
+
+	// a package which fails to parse is not taken for a cycle by its next importer
+	s, err = NewSpecFromPackagesE("m", map[string]map[string]string{
+		"a": {"a.go": `var X = `},
+		"b": {"b.go": `import "a"; var Y = a.X`},
+		"m": {"m.go": `import ("a"; "b"); var Z = a.X + b.Y`},
+	})
+	if err == nil || s == nil {
+		t.Fatal(`test failed`)
+	}
+	for _, e := range s.Errors() {
+		if strings.Contains(e.Msg, "import cycle") {
+			t.Error(`test failed`)
+		}
+	}
+}
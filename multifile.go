@@ -0,0 +1,58 @@
+package gospec
+
+import (
+	"errors"
+	"log"
+	"path"
+	"sort"
+)
+
+// NewSpecFromFiles is like NewSpec, but checks several files of one package together.
+// files maps file names to their code, the files are checked in the order of their names.
+// A file without package clause gets the package name of the other files, or "example" if none has one
+// (see WithPackageName).
+func NewSpecFromFiles(files map[string]string, opts ...Option) *Spec {
+	s, err := NewSpecFromFilesE(files, opts...)
+	if err != nil {
+		log.Panic(err)
+	}
+	return s
+}
+
+// NewSpecFromFilesE is like NewSpecFromFiles, but returns an error instead of panicking, see NewSpecE.
+func NewSpecFromFilesE(files map[string]string, opts ...Option) (*Spec, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files")
+	}
+	cfg := newConfig(opts)
+	packageName, sources := sourcesOf(files, "", cfg.packageName)
+	return newSpec(cfg.pathOf(packageName), packageName, sources, cfg)
+}
+
+// sourcesOf normalizes the files, in the order of their names, and prefixes their names with dir.
+// packageName is the name of the package, if none of the files has a package clause,
+// the last element of dir is used if it is empty.
+func sourcesOf(files map[string]string, dir string, packageName string) (string, []*sourceFile) {
+	if packageName == "" {
+		packageName = path.Base(dir)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name, hasClause, _ := scanPackageClause(files[name]); hasClause && name != "" {
+			packageName = name
+			break
+		}
+	}
+
+	sources := make([]*sourceFile, 0, len(names))
+	for _, name := range names {
+		src, _ := newSourceFile(path.Join(dir, name), files[name], packageName)
+		sources = append(sources, src)
+	}
+	return packageName, sources
+}
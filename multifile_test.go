@@ -0,0 +1,73 @@
+package gospec
+
+import (
+	"strings"
+	"testing"
+)
+
+// func NewSpecFromFiles(files map[string]string) *Spec
+func TestNewSpecFromFiles(t *testing.T) {
+	s := NewSpecFromFiles(map[string]string{
+		"a.go": `
+package haha
+import "fmt"
+type T struct{}
+func (T) String() string { return fmt.Sprint("T") }
+`,
+		"b.go": `
+import s "fmt"
+var x T
+var y s.Stringer = x
+`,
+	})
+
+	// package level objects of all files are in one package scope
+	if s.GetTypeObject("T").String() != "type haha.T struct{}" ||
+		s.GetTypeObject("x").String() != "var haha.x haha.T" ||
+		!s.Implements("x", "y") {
+		t.Error(`test failed`)
+	}
+
+	// imports are per-file, PkgName objects live in the File scope
+	if s.GetTypeObject("fmt") != nil || s.GetTypeObject("s") != nil {
+		t.Error(`test failed`)
+	}
+	s.SearchKind = SearchAll
+	if s.GetTypeObject("fmt").String() != "package fmt" || s.GetTypeObject("s").String() != "package s (\"fmt\")" {
+		t.Error(`test failed`)
+	}
+	if s.GetTypeObject("s").Parent() == s.GetTypeObject("fmt").Parent() {
+		t.Error(`test failed`)
+	}
+}
+
+// func NewSpecFromFilesE(files map[string]string) (*Spec, error)
+func TestNewSpecFromFilesE(t *testing.T) {
+	if _, err := NewSpecFromFilesE(nil); err == nil {
+		t.Error(`test failed`)
+	}
+
+	// an import is not visible in the other file
+	s, err := NewSpecFromFilesE(map[string]string{
+		"a.go": `import "fmt"
+var _ = fmt.Sprint()`,
+		"b.go": `var _ = fmt.Sprint()`,
+	})
+	if _, ok := err.(*CheckError); !ok {
+		t.Fatal(`test failed`)
+	}
+	ds := s.Diagnostics()
+	if len(ds) != 1 || ds[0].Pos.Filename != "b.go" || ds[0].Pos.Line != 1 || ds[0].Pos.Column != 9 ||
+		!strings.Contains(ds[0].Msg, "undefined: fmt") {
+		t.Error(`test failed`)
+	}
+
+	// files must belong to one package
+	_, err = NewSpecFromFilesE(map[string]string{
+		"a.go": `package a`,
+		"b.go": `package b`,
+	})
+	if _, ok := err.(*CheckError); !ok {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,181 @@
+package gospec
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// ObjectInfo describes an object declared in the code of a Spec, or in the universe scope.
+type ObjectInfo struct {
+	Object types.Object
+	// Kind is one of "const", "type", "var", "field", "func", "method", "package", "label", "builtin" and "nil".
+	Kind string
+	// Scope is the scope the object is declared in, fields and methods are not declared in scopes.
+	Scope *types.Scope
+	// Func is the function (or method, as "T.M") declaring the object, "" if it is not declared in a function.
+	Func string
+	// Pos is the position of the object in the code as it was given, see Diagnostic.
+	Pos token.Position
+}
+
+// Objects returns every object declared in the code of s, including fields, methods,
+// parameters and locals, in the order of their positions.
+func (s *Spec) Objects() []ObjectInfo {
+	funcs := s.funcScopes()
+	infos := make([]ObjectInfo, 0, len(s.info.Defs))
+	for _, o := range s.info.Defs {
+		if o != nil && !s.generated(o) {
+			infos = append(infos, s.objectInfo(o, funcs))
+		}
+	}
+	for _, o := range s.info.Implicits {
+		if _, ok := o.(*types.PkgName); ok { // imports without names
+			infos = append(infos, s.objectInfo(o, funcs))
+		}
+	}
+	sortObjectInfos(infos)
+	return infos
+}
+
+// ObjectAt returns the object defined or used by the identifier at line and column col of the code,
+// which is the code of the first file for NewSpecFromFiles. It returns nil if there is no identifier at the position.
+func (s *Spec) ObjectAt(line, col int) types.Object {
+	return s.ObjectAtFile(s.sources[0].name, line, col)
+}
+
+// ObjectAtFile is like ObjectAt, but finds the identifier in the file named filename.
+func (s *Spec) ObjectAtFile(filename string, line, col int) types.Object {
+	for _, idents := range []map[*ast.Ident]types.Object{s.info.Defs, s.info.Uses} {
+		for id, o := range idents {
+			pos := s.position(s.fset.Position(id.Pos()))
+			if o != nil && pos.Filename == filename && pos.Line == line &&
+				pos.Column <= col && col < pos.Column+len(id.Name) {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// LookupAll returns every object named name, declared in the universe scope,
+// the package scope, the file scopes or the local scopes of s, in the order of their positions.
+// Unlike GetTypeObject, it tells apart the locals of the same name in different functions.
+func (s *Spec) LookupAll(name string) []ObjectInfo {
+	var infos []ObjectInfo
+	if o := s.lookupUniverse(name); o != nil {
+		infos = append(infos, ObjectInfo{Object: o, Kind: objectKind(o), Scope: types.Universe})
+	}
+	funcs := s.funcScopes()
+	var matches []ObjectInfo
+	scopes := []*types.Scope{s.pkg.Scope()}
+	for len(scopes) > 0 {
+		sc := scopes[0]
+		scopes = scopes[1:]
+		if o := sc.Lookup(name); o != nil && !s.generated(o) {
+			matches = append(matches, s.objectInfo(o, funcs))
+		}
+		for i := 0; i < sc.NumChildren(); i++ {
+			scopes = append(scopes, sc.Child(i))
+		}
+	}
+	sortObjectInfos(matches)
+	return append(infos, matches...)
+}
+
+func (s *Spec) objectInfo(o types.Object, funcs map[*types.Scope]string) ObjectInfo {
+	info := ObjectInfo{Object: o, Kind: objectKind(o), Scope: o.Parent()}
+	if o.Pos().IsValid() {
+		info.Pos = s.position(s.fset.Position(o.Pos()))
+	}
+	if _, ok := o.(*types.Label); ok {
+		// labels are declared in a scope of their own, which is not a child of the function scope
+		for sc, name := range funcs {
+			if sc.Contains(o.Pos()) {
+				info.Func = name
+			}
+		}
+		return info
+	}
+	for sc := o.Parent(); sc != nil; sc = sc.Parent() {
+		if name, ok := funcs[sc]; ok {
+			info.Func = name
+			break
+		}
+	}
+	return info
+}
+
+// generated reports whether o is declared in the text inserted by normalizeCode,
+// such as the init function wrapping statements.
+func (s *Spec) generated(o types.Object) bool {
+	if !o.Pos().IsValid() || o.Parent() == types.Universe {
+		return false
+	}
+	pos := s.fset.Position(o.Pos())
+	return s.source(pos.Filename) != nil && s.position(pos).Line == 0
+}
+
+// funcScopes maps the scopes of the functions and methods declared in s to their names.
+// The init functions wrapping statements are not named, their locals are found as top-level names.
+func (s *Spec) funcScopes() map[*types.Scope]string {
+	funcs := make(map[*types.Scope]string)
+	for _, o := range s.info.Defs {
+		fn, ok := o.(*types.Func)
+		if !ok || fn.Scope() == nil || s.generated(fn) {
+			continue
+		}
+		name := fn.Name()
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			t := recv.Type()
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			if named, ok := t.(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+			}
+		}
+		funcs[fn.Scope()] = name
+	}
+	return funcs
+}
+
+func objectKind(o types.Object) string {
+	switch o := o.(type) {
+	case *types.Const:
+		return "const"
+	case *types.TypeName:
+		return "type"
+	case *types.Var:
+		if o.IsField() {
+			return "field"
+		}
+		return "var"
+	case *types.Func:
+		if o.Type().(*types.Signature).Recv() != nil {
+			return "method"
+		}
+		return "func"
+	case *types.PkgName:
+		return "package"
+	case *types.Label:
+		return "label"
+	case *types.Builtin:
+		return "builtin"
+	case *types.Nil:
+		return "nil"
+	default:
+		return ""
+	}
+}
+
+func sortObjectInfos(infos []ObjectInfo) {
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i].Pos, infos[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+}
@@ -0,0 +1,126 @@
+package gospec
+
+import (
+	"fmt"
+	"go/types"
+	"testing"
+)
+
+const objectsCode = `
+import "fmt"
+type T struct{ x int }
+func (t *T) M(y int) {
+	x := y
+	_ = x
+}
+func main() {
+	var x = 2.0
+	fmt.Println(x)
+L:
+	for {
+		break L
+	}
+}
+func other() {
+	var x int
+	_ = x
+}`
+
+// func (s *Spec) Objects() []ObjectInfo
+func TestSpec_Objects(t *testing.T) {
+	s := NewSpec(objectsCode)
+	var got []string
+	for _, info := range s.Objects() {
+		got = append(got, fmt.Sprintf("%d:%d %s %s %s", info.Pos.Line, info.Pos.Column, info.Kind, info.Object.Name(), info.Func))
+	}
+	want := []string{
+		"2:8 package fmt ",
+		"3:6 type T ",
+		"3:16 field x ",
+		"4:7 var t T.M",
+		"4:13 method M ",
+		"4:15 var y T.M",
+		"5:2 var x T.M",
+		"8:6 func main ",
+		"9:6 var x main",
+		"11:1 label L main",
+		"16:6 func other ",
+		"17:6 var x other",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q", got)
+	}
+
+	// the code wrapping snippets is not reported
+	for code, want := range map[string][]string{
+		"x := 1; if x > 0 { y := x; _ = y }": {"1:1 var x ", "1:20 var y "},
+		"1 << 10":                            nil,
+	} {
+		got = nil
+		for _, info := range NewSpec(code).Objects() {
+			got = append(got, fmt.Sprintf("%d:%d %s %s %s", info.Pos.Line, info.Pos.Column, info.Kind, info.Object.Name(), info.Func))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %q", got)
+		}
+	}
+}
+
+// func (s *Spec) ObjectAt(line, col int) types.Object
+func TestSpec_ObjectAt(t *testing.T) {
+	s := NewSpec(objectsCode)
+	for _, v := range []struct {
+		line, col int
+		want      string
+	}{
+		{9, 6, "var x float64"},
+		{10, 14, "var x float64"},
+		{10, 2, "package fmt"},
+		{10, 6, "func fmt.Println(a ...any) (n int, err error)"},
+		{17, 6, "var x int"},
+		{18, 6, "var x int"},
+		{3, 6, "type example.T struct{x int}"},
+		{3, 7, "<nil>"},
+		{1, 1, "<nil>"},
+	} {
+		if got := fmt.Sprint(s.ObjectAt(v.line, v.col)); got != v.want {
+			t.Errorf("object at %d:%d got %s, want %s", v.line, v.col, got, v.want)
+		}
+	}
+
+	s = NewSpecFromFiles(map[string]string{"a.go": `var a int`, "b.go": `var b = a`})
+	if s.ObjectAt(1, 5).Name() != "a" || s.ObjectAtFile("b.go", 1, 9).Name() != "a" || s.ObjectAtFile("b.go", 1, 5).Name() != "b" {
+		t.Error(`test failed`)
+	}
+}
+
+// func (s *Spec) LookupAll(name string) []ObjectInfo
+func TestSpec_LookupAll(t *testing.T) {
+	s := NewSpec(objectsCode)
+	var got []string
+	for _, info := range s.LookupAll("x") {
+		got = append(got, fmt.Sprintf("%d:%d %s %s", info.Pos.Line, info.Pos.Column, info.Object, info.Func))
+	}
+	want := []string{
+		"5:2 var x int T.M",
+		"9:6 var x float64 main",
+		"17:6 var x int other",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q", got)
+	}
+
+	infos := s.LookupAll("int")
+	if len(infos) != 1 || infos[0].Scope != types.Universe || infos[0].Kind != "type" {
+		t.Error(`test failed`)
+	}
+	if len(s.LookupAll("notExists")) != 0 {
+		t.Error(`test failed`)
+	}
+
+	// the locals of statements are not declared in a function
+	infos = NewSpec(`x := 1; if x > 0 { x := "s"; _ = x }`).LookupAll("x")
+	if len(infos) != 2 || infos[0].Func != "" || infos[1].Func != "" {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,81 @@
+package gospec
+
+import "go/types"
+
+// Option configures a Spec, see NewSpec.
+type Option func(*config)
+
+// config is the configuration of a Spec
+type config struct {
+	searchKind  SearchKind
+	importer    types.Importer // GetImporter(ImportAuto) if nil
+	goVersion   string         // passed to types.Config.GoVersion
+	sizes       types.Sizes    // passed to types.Config.Sizes
+	packageName string         // the package name of code without package clause
+	packagePath string         // the import path of the package, its name if empty
+	oracle      RelationOracle // RulesOracle if nil
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		searchKind:  SearchPackageAndUniverse, // default search in universe and pkg scope
+		packageName: defaultPackageName,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// pathOf returns the import path of the package named packageName.
+func (cfg *config) pathOf(packageName string) string {
+	if cfg.packagePath != "" {
+		return cfg.packagePath
+	}
+	return packageName
+}
+
+// WithSearchKind sets the SearchKind of the Spec, SearchPackageAndUniverse by default.
+func WithSearchKind(kind SearchKind) Option {
+	return func(cfg *config) {
+		cfg.searchKind = kind
+	}
+}
+
+// WithImporter sets the importer of the Spec, GetImporter(ImportAuto) by default.
+func WithImporter(imp types.Importer) Option {
+	return func(cfg *config) {
+		cfg.importer = imp
+	}
+}
+
+// WithGoVersion checks the code by the rules of the Go version, such as "go1.17" or "1.17",
+// instead of the version of the toolchain which compiled this package.
+func WithGoVersion(version string) Option {
+	return func(cfg *config) {
+		cfg.goVersion = normalizeGoVersion(version)
+	}
+}
+
+// WithSizes sets the sizes of types used by the checker, such as types.SizesFor("gc", "386"),
+// which matter to unsafe.Sizeof and the like.
+func WithSizes(sizes types.Sizes) Option {
+	return func(cfg *config) {
+		cfg.sizes = sizes
+	}
+}
+
+// WithPackageName sets the package name of code without package clause, "example" by default.
+func WithPackageName(name string) Option {
+	return func(cfg *config) {
+		cfg.packageName = name
+	}
+}
+
+// WithPackagePath sets the import path of the package, which is its name by default.
+// Types of packages with different paths are different, even if they have the same name.
+func WithPackagePath(path string) Option {
+	return func(cfg *config) {
+		cfg.packagePath = path
+	}
+}
@@ -0,0 +1,58 @@
+package gospec
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestNewConfig(t *testing.T) {
+	cfg := newConfig(nil)
+	if cfg.searchKind != SearchPackageAndUniverse || cfg.importer != nil || cfg.goVersion != "" || cfg.sizes != nil ||
+		cfg.packageName != "example" || cfg.pathOf("haha") != "haha" {
+		t.Error(`test failed`)
+	}
+}
+
+// func WithSearchKind(kind SearchKind) Option
+func TestWithSearchKind(t *testing.T) {
+	code := `func main() { var b int; _ = b }`
+	if NewSpec(code).GetTypeObject("b") != nil || NewSpec(code, WithSearchKind(SearchAll)).GetTypeObject("b") == nil {
+		t.Error(`test failed`)
+	}
+}
+
+// func WithSizes(sizes types.Sizes) Option
+func TestWithSizes(t *testing.T) {
+	code := `import "unsafe"; const size = unsafe.Sizeof(uintptr(0))`
+	if NewSpec(code, WithSizes(types.SizesFor("gc", "386"))).ConstValue("size").String() != "4" ||
+		NewSpec(code, WithSizes(types.SizesFor("gc", "amd64"))).ConstValue("size").String() != "8" {
+		t.Error(`test failed`)
+	}
+}
+
+// func WithPackageName(name string) Option
+// func WithPackagePath(path string) Option
+func TestWithPackageName(t *testing.T) {
+	s := NewSpec(`type T int`, WithPackageName("haha"))
+	if s.GetType("T").String() != "haha.T" || s.pkg.Name() != "haha" {
+		t.Error(`test failed`)
+	}
+	// the name in the package clause wins
+	s = NewSpec(`package hoho; type T int`, WithPackageName("haha"))
+	if s.GetType("T").String() != "hoho.T" {
+		t.Error(`test failed`)
+	}
+
+	s = NewSpec(`type T int`, WithPackagePath("example.com/haha"))
+	if s.GetType("T").String() != "example.com/haha.T" || s.pkg.Name() != "example" {
+		t.Error(`test failed`)
+	}
+	// non-exported field names from packages with different paths are different
+	code := `var x struct{ f int }`
+	a1 := NewSpec(code, WithPackagePath("example.com/a"))
+	a2 := NewSpec(code, WithPackagePath("example.com/a"))
+	b := NewSpec(code, WithPackagePath("example.com/b"))
+	if !Identical(a1.GetType("x"), a2.GetType("x")) || Identical(a1.GetType("x"), b.GetType("x")) {
+		t.Error(`test failed`)
+	}
+}
@@ -0,0 +1,254 @@
+package gospec
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+)
+
+// Operand is a value a relation is about.
+type Operand struct {
+	Type  types.Type
+	Value constant.Value // the value of a constant, nil if it is not a constant
+}
+
+// RelationOracle answers the relations of the spec for a Spec, see WithOracle.
+// The types are types of the Spec, such as the ones returned by GetType.
+type RelationOracle interface {
+	Identical(s *Spec, V, T types.Type) (bool, error)
+	Assignable(s *Spec, x Operand, T types.Type) (bool, error)
+	Convertible(s *Spec, x Operand, T types.Type) (bool, error)
+	Comparable(s *Spec, T types.Type) (bool, error)
+	// Representable is asked only about constants and basic types.
+	Representable(s *Spec, x Operand, T *types.Basic) (bool, error)
+	Implements(s *Spec, V, T types.Type) (bool, error)
+}
+
+var (
+	// RulesOracle answers by the rules of the spec implemented in this package, it is the default.
+	RulesOracle RelationOracle = rulesOracle{}
+	// LinknameOracle answers by the unexported functions of go/types, where it has them,
+	// it fails with ErrIncompatibleToolchain if they do not work with the running toolchain.
+	LinknameOracle RelationOracle = linknameOracle{}
+	// ProbeOracle answers by type-checking a program synthesized from the query, see ProbeAssignment.
+	// Queries which can not be written as a program are answered by RulesOracle.
+	ProbeOracle RelationOracle = fallbackOracle{probeOracle{}, rulesOracle{}}
+)
+
+// WithOracle sets the RelationOracle which answers Identical, Assignment, Conversion, Comparable,
+// Representable and Implements of the Spec, RulesOracle by default.
+func WithOracle(o RelationOracle) Option {
+	return func(cfg *config) {
+		cfg.oracle = o
+	}
+}
+
+// CrossCheck returns a RelationOracle which asks both a and b, and returns the answer of a.
+// If they disagree, it returns a *DisagreementError too.
+func CrossCheck(a, b RelationOracle) RelationOracle {
+	return crossCheckOracle{a, b}
+}
+
+// mustAnswer returns ok, it panics if err is not nil.
+func mustAnswer(ok bool, err error) bool {
+	if err != nil {
+		panic(err.Error())
+	}
+	return ok
+}
+
+type rulesOracle struct{}
+
+func (rulesOracle) Identical(s *Spec, V, T types.Type) (bool, error) {
+	return types.Identical(V, T), nil
+}
+
+func (rulesOracle) Assignable(s *Spec, x Operand, T types.Type) (bool, error) {
+	return assignable(x.Type, x.Value, T, s.sizes), nil
+}
+
+func (rulesOracle) Convertible(s *Spec, x Operand, T types.Type) (bool, error) {
+	_, ok := convert(x.Type, x.Value, T, s.sizes, s.goVersion)
+	return ok, nil
+}
+
+func (rulesOracle) Comparable(s *Spec, T types.Type) (bool, error) {
+	return types.Comparable(T), nil
+}
+
+func (rulesOracle) Representable(s *Spec, x Operand, T *types.Basic) (bool, error) {
+	_, failure := representation(x.Value, T, s.sizes)
+	return failure == RepresentOK, nil
+}
+
+func (rulesOracle) Implements(s *Spec, V, T types.Type) (bool, error) {
+	return implements(V, T), nil
+}
+
+// linknameOracle uses the public go/types API where it has one.
+type linknameOracle struct {
+	rulesOracle
+}
+
+func (linknameOracle) operand(x Operand) *operand {
+	if x.Value != nil {
+		return &operand{mode: constant_, typ: x.Type, val: x.Value}
+	}
+	return &operand{mode: value, typ: x.Type}
+}
+
+// the linked functions change the state of the checker of s
+
+func (o linknameOracle) Assignable(s *Spec, x Operand, T types.Type) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return linkAssignment(s.checker, o.operand(x), T)
+}
+
+func (o linknameOracle) Convertible(s *Spec, x Operand, T types.Type) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	val, ok, err := linkConversion(s.checker, o.operand(x), T)
+	// the default type of an untyped constant converted to a non-constant type is checked later by go/types
+	if ok && val == nil && x.Value != nil && IsUntyped(x.Type) {
+		ok = representableConst(x.Value, types.Default(x.Type).(*types.Basic), s.sizes, nil)
+	}
+	return ok, err
+}
+
+func (o linknameOracle) Representable(s *Spec, x Operand, T *types.Basic) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok, err := linkRepresentable(s.checker, o.operand(x), T)
+	return ok, err
+}
+
+func (linknameOracle) Implements(s *Spec, V, T types.Type) (bool, error) {
+	ti, ok := T.Underlying().(*types.Interface)
+	return ok && types.Implements(V, ti), nil
+}
+
+type probeOracle struct{}
+
+func (probeOracle) Identical(s *Spec, V, T types.Type) (bool, error) {
+	ok, _, err := s.probeIdentical(V, T)
+	return ok, err
+}
+
+func (probeOracle) Assignable(s *Spec, x Operand, T types.Type) (bool, error) {
+	ok, _, err := s.probeAssignment(x.Type, x.Value, T)
+	return ok, err
+}
+
+func (probeOracle) Convertible(s *Spec, x Operand, T types.Type) (bool, error) {
+	ok, _, err := s.probeConversion(x.Type, x.Value, T)
+	return ok, err
+}
+
+func (probeOracle) Comparable(s *Spec, T types.Type) (bool, error) {
+	ok, _, err := s.probeComparable(T)
+	return ok, err
+}
+
+func (probeOracle) Representable(s *Spec, x Operand, T *types.Basic) (bool, error) {
+	ok, _, err := s.probeRepresentable(x.Type, x.Value, T)
+	return ok, err
+}
+
+func (probeOracle) Implements(s *Spec, V, T types.Type) (bool, error) {
+	ok, _, err := s.probeImplements(V, T)
+	return ok, err
+}
+
+// fallbackOracle asks the fallback if the primary fails.
+type fallbackOracle struct {
+	primary, fallback RelationOracle
+}
+
+func (o fallbackOracle) answer(ask func(RelationOracle) (bool, error)) (bool, error) {
+	if ok, err := ask(o.primary); err == nil {
+		return ok, nil
+	}
+	return ask(o.fallback)
+}
+
+func (o fallbackOracle) Identical(s *Spec, V, T types.Type) (bool, error) {
+	ask := func(o RelationOracle) (bool, error) { return o.Identical(s, V, T) }
+	return o.answer(ask)
+}
+
+func (o fallbackOracle) Assignable(s *Spec, x Operand, T types.Type) (bool, error) {
+	ask := func(o RelationOracle) (bool, error) { return o.Assignable(s, x, T) }
+	return o.answer(ask)
+}
+
+func (o fallbackOracle) Convertible(s *Spec, x Operand, T types.Type) (bool, error) {
+	ask := func(o RelationOracle) (bool, error) { return o.Convertible(s, x, T) }
+	return o.answer(ask)
+}
+
+func (o fallbackOracle) Comparable(s *Spec, T types.Type) (bool, error) {
+	ask := func(o RelationOracle) (bool, error) { return o.Comparable(s, T) }
+	return o.answer(ask)
+}
+
+func (o fallbackOracle) Representable(s *Spec, x Operand, T *types.Basic) (bool, error) {
+	ask := func(o RelationOracle) (bool, error) { return o.Representable(s, x, T) }
+	return o.answer(ask)
+}
+
+func (o fallbackOracle) Implements(s *Spec, V, T types.Type) (bool, error) {
+	ask := func(o RelationOracle) (bool, error) { return o.Implements(s, V, T) }
+	return o.answer(ask)
+}
+
+type crossCheckOracle struct {
+	a, b RelationOracle
+}
+
+// answer asks a and b, and returns the answer of a, with a *DisagreementError if b does not agree.
+func (o crossCheckOracle) answer(query string, ask func(RelationOracle) (bool, error)) (bool, error) {
+	a, err := ask(o.a)
+	if err != nil {
+		return a, err
+	}
+	b, err := ask(o.b)
+	if err != nil {
+		return a, err
+	}
+	if a != b {
+		return a, &DisagreementError{Query: query, A: a, B: b}
+	}
+	return a, nil
+}
+
+func (o crossCheckOracle) Identical(s *Spec, V, T types.Type) (bool, error) {
+	return o.answer(fmt.Sprintf("Identical(%s, %s)", V, T), func(o RelationOracle) (bool, error) { return o.Identical(s, V, T) })
+}
+
+func (o crossCheckOracle) Assignable(s *Spec, x Operand, T types.Type) (bool, error) {
+	return o.answer(fmt.Sprintf("Assignable(%s, %s)", x, T), func(o RelationOracle) (bool, error) { return o.Assignable(s, x, T) })
+}
+
+func (o crossCheckOracle) Convertible(s *Spec, x Operand, T types.Type) (bool, error) {
+	return o.answer(fmt.Sprintf("Convertible(%s, %s)", x, T), func(o RelationOracle) (bool, error) { return o.Convertible(s, x, T) })
+}
+
+func (o crossCheckOracle) Comparable(s *Spec, T types.Type) (bool, error) {
+	return o.answer(fmt.Sprintf("Comparable(%s)", T), func(o RelationOracle) (bool, error) { return o.Comparable(s, T) })
+}
+
+func (o crossCheckOracle) Representable(s *Spec, x Operand, T *types.Basic) (bool, error) {
+	return o.answer(fmt.Sprintf("Representable(%s, %s)", x, T), func(o RelationOracle) (bool, error) { return o.Representable(s, x, T) })
+}
+
+func (o crossCheckOracle) Implements(s *Spec, V, T types.Type) (bool, error) {
+	return o.answer(fmt.Sprintf("Implements(%s, %s)", V, T), func(o RelationOracle) (bool, error) { return o.Implements(s, V, T) })
+}
+
+func (x Operand) String() string {
+	if x.Value != nil {
+		return fmt.Sprintf("%s (constant of type %s)", x.Value, x.Type)
+	}
+	return fmt.Sprintf("value of type %s", x.Type)
+}
@@ -0,0 +1,64 @@
+package gospec
+
+import (
+	"errors"
+	"go/types"
+	"testing"
+)
+
+// the oracles must agree with each other on the corpus
+func TestCrossCheck(t *testing.T) {
+	for _, other := range []RelationOracle{LinknameOracle, ProbeOracle} {
+		s := NewSpec(assignmentCorpus, WithSearchKind(SearchAll), WithOracle(CrossCheck(RulesOracle, other)))
+		ask := func(query func()) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Error(r)
+				}
+			}()
+			query()
+		}
+		for _, typ := range assignmentTypes {
+			for _, v := range assignmentValues {
+				ask(func() { s.Assignment(v, typ) })
+				ask(func() { s.Conversion(v, typ) })
+				ask(func() { s.Representable(v, typ) })
+			}
+			for _, typ2 := range assignmentTypes {
+				ask(func() { s.Identical(typ, typ2) })
+				ask(func() { s.Implements(typ, typ2) })
+			}
+			ask(func() { s.Comparable(typ) })
+		}
+	}
+}
+
+// liar answers the opposite of RulesOracle about assignability
+type liar struct {
+	rulesOracle
+}
+
+func (liar) Assignable(s *Spec, x Operand, T types.Type) (bool, error) {
+	ok, err := RulesOracle.Assignable(s, x, T)
+	return !ok, err
+}
+
+func TestWithOracle(t *testing.T) {
+	s := NewSpec(`var x int`, WithOracle(liar{}))
+	if s.Assignment("x", "int") || !s.Assignment("x", "string") {
+		t.Error(`test failed`)
+	}
+
+	s = NewSpec(`var x int`, WithOracle(CrossCheck(RulesOracle, liar{})))
+	ok, err := s.oracle.Assignable(s, Operand{Type: s.MustGetValidType("x")}, types.Typ[types.Int])
+	var e *DisagreementError
+	if !ok || !errors.As(err, &e) || e.Query != "Assignable(value of type int, int)" || !e.A || e.B {
+		t.Error(`test failed`)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error(`test failed`)
+		}
+	}()
+	s.Assignment("x", "int")
+}
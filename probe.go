@@ -0,0 +1,314 @@
+package gospec
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// ProbeAssignment answers Assignment by type-checking, with the code of s, a file like
+//
+//	func _() {
+//		var _ T = v
+//	}
+//
+// where v is a variable of the type of v, or the value of v if it is a constant.
+// It returns the verdict and the file, a witness of the verdict.
+// An error is returned if the query can not be written in the file,
+// such as for a type declared in a function, or a type parameter.
+func (s *Spec) ProbeAssignment(v, t string) (bool, string, error) {
+	x := s.mustGetOperand(v)
+	return s.probeAssignment(x.typ, x.val, s.MustGetValidType(t))
+}
+
+// ProbeConversion answers Conversion like ProbeAssignment, by type-checking "_ = (T)(v)".
+func (s *Spec) ProbeConversion(v, t string) (bool, string, error) {
+	x := s.mustGetOperand(v)
+	return s.probeConversion(x.typ, x.val, s.MustGetValidType(t))
+}
+
+// ProbeComparable answers Comparable like ProbeAssignment, by type-checking "_ = a == b" for a and b of type v.
+func (s *Spec) ProbeComparable(v string) (bool, string, error) {
+	return s.probeComparable(s.MustGetValidType(v))
+}
+
+func (s *Spec) probeAssignment(V types.Type, val constant.Value, T types.Type) (bool, string, error) {
+	p := newProbe(s)
+	x, t := p.value(V, val), p.typ(T)
+	return p.check(fmt.Sprintf("var _ %s = %s", t, x))
+}
+
+func (s *Spec) probeConversion(V types.Type, val constant.Value, T types.Type) (bool, string, error) {
+	p := newProbe(s)
+	x, t := p.value(V, val), p.typ(T)
+	return p.check(fmt.Sprintf("_ = (%s)(%s)", t, x))
+}
+
+// probeIdentical tells V and T are identical, if *V is assignable to *T.
+func (s *Spec) probeIdentical(V, T types.Type) (bool, string, error) {
+	p := newProbe(s)
+	v, t := p.typ(V), p.typ(T)
+	return p.check(fmt.Sprintf("var _ *%s = (*%s)(nil)", t, v))
+}
+
+// probeRepresentable tells the constant val is representable by T, if it can be a constant of type T.
+func (s *Spec) probeRepresentable(V types.Type, val constant.Value, T *types.Basic) (bool, string, error) {
+	p := newProbe(s)
+	return p.check(fmt.Sprintf("const _ %s = %s", p.typ(T), constLiteral(V, val)))
+}
+
+// probeImplements tells V implements T, if a value of type V is assignable to T.
+// T must not be a constraint interface, which can not be the type of a variable.
+func (s *Spec) probeImplements(V, T types.Type) (bool, string, error) {
+	ti, ok := T.Underlying().(*types.Interface)
+	if !ok {
+		return false, "", fmt.Errorf("%s is not an interface", T)
+	}
+	if !ti.IsMethodSet() {
+		return false, "", fmt.Errorf("constraint interface %s can not be probed", T)
+	}
+	p := newProbe(s)
+	x, t := p.value(V, nil), p.typ(T)
+	return p.check(fmt.Sprintf("var _ %s = %s", t, x))
+}
+
+func (s *Spec) probeComparable(T types.Type) (bool, string, error) {
+	p := newProbe(s)
+	t := p.typ(T)
+	return p.check(fmt.Sprintf("var _a, _b %s", t), "_ = _a == _b")
+}
+
+// probe synthesizes a file of the package of a Spec. Its names begin with "_",
+// so they do not hide the names of the package.
+type probe struct {
+	s       *Spec
+	imports map[*types.Package]string // the names of imported packages
+	pkgs    []*types.Package          // the imported packages, in the order they are named
+	vars    []string                  // the declarations of variables
+	err     error                     // the first reason the query can not be written
+}
+
+func newProbe(s *Spec) *probe {
+	return &probe{s: s, imports: make(map[*types.Package]string)}
+}
+
+// typ returns the type expression of t.
+func (p *probe) typ(t types.Type) string {
+	if err := p.writable(t, nil); err != nil && p.err == nil {
+		p.err = err
+	}
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == p.s.pkg {
+			return ""
+		}
+		if _, ok := p.imports[pkg]; !ok {
+			p.imports[pkg] = "_p" + strconv.Itoa(len(p.pkgs))
+			p.pkgs = append(p.pkgs, pkg)
+		}
+		return p.imports[pkg]
+	})
+}
+
+// writable returns an error if t can not be written in the file.
+func (p *probe) writable(t types.Type, seen map[types.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	if seen == nil {
+		seen = make(map[types.Type]bool)
+	}
+	seen[t] = true
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		if t.Info()&types.IsUntyped != 0 || t.Kind() == types.Invalid {
+			return fmt.Errorf("%s can not be probed", t)
+		}
+	case *types.TypeParam:
+		return fmt.Errorf("type parameter %s can not be probed", t)
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && obj.Parent() != obj.Pkg().Scope() {
+			return fmt.Errorf("local type %s can not be probed", t)
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if err := p.writable(t.TypeArgs().At(i), seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *types.Pointer:
+		return p.writable(t.Elem(), seen)
+	case *types.Slice:
+		return p.writable(t.Elem(), seen)
+	case *types.Array:
+		return p.writable(t.Elem(), seen)
+	case *types.Chan:
+		return p.writable(t.Elem(), seen)
+	case *types.Map:
+		if err := p.writable(t.Key(), seen); err != nil {
+			return err
+		}
+		return p.writable(t.Elem(), seen)
+	case *types.Signature:
+		if err := p.writable(t.Params(), seen); err != nil {
+			return err
+		}
+		return p.writable(t.Results(), seen)
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			if err := p.writable(t.At(i).Type(), seen); err != nil {
+				return err
+			}
+		}
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if f := t.Field(i); !f.Exported() && f.Pkg() != p.s.pkg {
+				return fmt.Errorf("unexported field %s of package %s can not be probed", f.Name(), f.Pkg().Path())
+			}
+			if err := p.writable(t.Field(i).Type(), seen); err != nil {
+				return err
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			if m := t.ExplicitMethod(i); !m.Exported() && m.Pkg() != p.s.pkg {
+				return fmt.Errorf("unexported method %s of package %s can not be probed", m.Name(), m.Pkg().Path())
+			}
+			if err := p.writable(t.ExplicitMethod(i).Type(), seen); err != nil {
+				return err
+			}
+		}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if err := p.writable(t.EmbeddedType(i), seen); err != nil {
+				return err
+			}
+		}
+	case *types.Union:
+		for i := 0; i < t.Len(); i++ {
+			if err := p.writable(t.Term(i).Type(), seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value returns an expression of a value of type V, val is the value of a constant, or nil.
+func (p *probe) value(V types.Type, val constant.Value) string {
+	if val != nil {
+		lit := constLiteral(V, val)
+		if IsTyped(V) {
+			return fmt.Sprintf("(%s)(%s)", p.typ(V), lit)
+		}
+		return lit
+	}
+	if b, ok := V.(*types.Basic); ok && b.Info()&types.IsUntyped != 0 {
+		switch b.Kind() {
+		case types.UntypedNil:
+			return "nil"
+		case types.UntypedBool:
+			return "(*new(int) == 0)"
+		case types.UntypedInt:
+			return "(1 << *new(uint))"
+		case types.UntypedRune:
+			return "('a' << *new(uint))"
+		default:
+			if p.err == nil {
+				p.err = fmt.Errorf("non-constant %s value can not be probed", b)
+			}
+			return "nil"
+		}
+	}
+	name := "_v" + strconv.Itoa(len(p.vars))
+	p.vars = append(p.vars, fmt.Sprintf("var %s %s", name, p.typ(V)))
+	return name
+}
+
+// constLiteral returns a constant expression of val, it is untyped, of the kind of V.
+func constLiteral(V types.Type, val constant.Value) string {
+	switch {
+	case IsBoolean(V):
+		return val.ExactString()
+	case IsString(V):
+		return val.ExactString()
+	case IsComplex(V):
+		return fmt.Sprintf("(%s + %s*1i)", floatLiteral(constant.Real(val)), floatLiteral(constant.Imag(val)))
+	case IsFloat(V):
+		return floatLiteral(val)
+	case V.Underlying() == types.Typ[types.UntypedRune]:
+		return fmt.Sprintf("('\\x00' + %s)", intLiteral(val))
+	default:
+		return intLiteral(val)
+	}
+}
+
+func intLiteral(val constant.Value) string {
+	return "(" + constant.ToInt(val).ExactString() + ")"
+}
+
+// floatLiteral returns val as an exact fraction, such as "(1.0 / 3.0)".
+// Both are floating-point literals, untyped integer constants are limited to 512 bits by go/types,
+// which the denominator of a tiny value, such as -1e-1000, exceeds.
+func floatLiteral(val constant.Value) string {
+	val = constant.ToFloat(val)
+	num, denom := constant.Num(val).ExactString(), constant.Denom(val).ExactString()
+	if denom == "1" {
+		return "(" + num + ".0)"
+	}
+	return "(" + num + ".0 / " + denom + ".0)"
+}
+
+// check type-checks the file of stmts with the code of the Spec,
+// it reports whether the file has no errors, and returns the file.
+func (p *probe) check(stmts ...string) (ok bool, witness string, err error) {
+	if p.err != nil {
+		return false, "", p.err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n", p.s.pkg.Name())
+	if len(p.pkgs) > 0 {
+		b.WriteString("\nimport (\n")
+		for _, pkg := range p.pkgs {
+			fmt.Fprintf(&b, "\t%s %q\n", p.imports[pkg], pkg.Path())
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString("\nfunc _() {\n")
+	for _, stmt := range append(p.vars, stmts...) {
+		b.WriteString("\t" + stmt + "\n")
+	}
+	b.WriteString("}\n")
+	witness = b.String()
+
+	const filename = "gospec_probe.go"
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	defer func() {
+		// go/types panics on some code it does not expect, the probe can not answer then
+		if r := recover(); r != nil {
+			ok, err = false, fmt.Errorf("check probe failed: %v", r)
+		}
+	}()
+	fset := p.s.scratchFileSet()
+	f, err := parser.ParseFile(fset, filename, witness, 0)
+	if err != nil {
+		return false, witness, fmt.Errorf("parse probe failed: %w", err)
+	}
+	ok = true
+	conf := &types.Config{
+		Importer:  p.s.importer,
+		GoVersion: p.s.goVersion,
+		Sizes:     p.s.sizes,
+		Error: func(err error) {
+			if e, isTypeErr := err.(types.Error); isTypeErr && e.Fset.Position(e.Pos).Filename == filename {
+				ok = false
+			}
+		},
+	}
+	pkg := types.NewPackage(p.s.pkg.Path(), p.s.pkg.Name())
+	_ = types.NewChecker(conf, fset, pkg, nil).Files(append(append([]*ast.File{}, p.s.files...), f))
+	return ok, witness, nil
+}
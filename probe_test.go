@@ -0,0 +1,66 @@
+package gospec
+
+import (
+	"strings"
+	"testing"
+)
+
+// func (s *Spec) ProbeAssignment(v, t string) (bool, string, error)
+func TestSpec_ProbeAssignment(t *testing.T) {
+	s := NewSpec(`import "fmt"; type T int; var x T; var p fmt.Stringer`)
+	ok, witness, err := s.ProbeAssignment("x", "int")
+	if err != nil || ok || !strings.Contains(witness, "var _v0 T\n\tvar _ int = _v0") {
+		t.Error(`test failed`)
+	}
+	ok, witness, err = s.ProbeAssignment("1 << 10", "T")
+	if err != nil || !ok || !strings.Contains(witness, "var _ T = (1024)") {
+		t.Error(`test failed`)
+	}
+	ok, witness, err = s.ProbeAssignment("p", "interface{ String() string }")
+	if err != nil || !ok || !strings.Contains(witness, `_p0 "fmt"`) {
+		t.Error(`test failed`)
+	}
+	if _, _, err = NewSpec(`func f[P any](p P) {}`, WithSearchKind(SearchAll)).ProbeAssignment("f.p", "int"); err == nil {
+		t.Error(`test failed`)
+	}
+}
+
+// the probes must agree with the rules
+func TestProbeMatchesRules(t *testing.T) {
+	s := NewSpec(assignmentCorpus, WithSearchKind(SearchAll))
+	for _, v := range assignmentValues {
+		x := s.mustGetOperand(v)
+		for _, typ := range assignmentTypes {
+			T := s.MustGetValidType(typ)
+			if ok, witness, err := s.probeAssignment(x.typ, x.val, T); err == nil && ok != assignable(x.typ, x.val, T, nil) {
+				t.Errorf("probe of Assignment(%s, %s) is %v:\n%s", v, typ, ok, witness)
+			}
+			_, want := convert(x.typ, x.val, T, nil, "")
+			if ok, witness, err := s.probeConversion(x.typ, x.val, T); err == nil && ok != want {
+				t.Errorf("probe of Conversion(%s, %s) is %v:\n%s", v, typ, ok, witness)
+			}
+		}
+	}
+	for _, typ := range append(assignmentTypes, "func()", "[]int", "struct{ f func() }", "[2]map[int]int", "interface{ m() }") {
+		if ok, witness, err := s.ProbeComparable(typ); err == nil && ok != Comparable(s.MustGetValidType(typ)) {
+			t.Errorf("probe of Comparable(%s) is %v:\n%s", typ, ok, witness)
+		}
+	}
+}
+
+// the relations of a Spec are answered by probes with WithOracle(ProbeOracle)
+func TestProbeOracle(t *testing.T) {
+	s := NewSpec(`type T int8; const c = 1.5; var x T`, WithOracle(ProbeOracle))
+	if !s.Assignment("x", "T") || s.Assignment("c", "T") || !s.Conversion("x", "float64") || s.Conversion("c", "T") ||
+		!s.Comparable("T") || s.Comparable("[]T") {
+		t.Error(`test failed`)
+	}
+	// the denominator of a tiny constant is larger than an untyped integer constant can be
+	s = NewSpec(`import "unsafe"; const tiny = -1e-1000; var p unsafe.Pointer`, WithOracle(ProbeOracle))
+	if !s.Assignment("tiny", "float64") || !s.Representable("tiny", "float32") || s.Assignment("tiny", "unsafe.Pointer") {
+		t.Error(`test failed`)
+	}
+	if ok, _, err := s.ProbeAssignment("tiny", "unsafe.Pointer"); err != nil || ok {
+		t.Error(`test failed`)
+	}
+}
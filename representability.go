@@ -1,31 +1,266 @@
 package gospec
 
-import "go/types"
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+)
 
+// RepresentFailure is the reason a constant is not representable by a value of a type.
+type RepresentFailure int
+
+const (
+	RepresentOK RepresentFailure = iota
+	// RepresentOverflow is for a value out of the range of the type, such as 1024 in byte or 1e1000 in float64
+	RepresentOverflow
+	// RepresentTruncated is for a value whose imaginary part would be lost, such as 42i in float32
+	RepresentTruncated
+	// RepresentNotInteger is for a value with a fractional part in an integer type, such as 1.1 in int
+	RepresentNotInteger
+	// RepresentMismatch is for a value of another kind, such as 0 in bool or 'a' in string,
+	// or a value which is not a constant, or a type which is not a basic type
+	RepresentMismatch
+)
+
+var representFailureString = [...]string{
+	RepresentOK:         "ok",
+	RepresentOverflow:   "overflow",
+	RepresentTruncated:  "truncated",
+	RepresentNotInteger: "not an integer",
+	RepresentMismatch:   "mismatch",
+}
+
+func (f RepresentFailure) String() string {
+	if f < 0 || int(f) >= len(representFailureString) {
+		return "invalid"
+	}
+	return representFailureString[f]
+}
+
+// Representable reports whether the constant v is representable by a value of type t.
+// v may be a name, or a constant expression such as "2.718281828459045"; t may be a name or a type expression.
 func (s *Spec) Representable(v, t string) bool {
-	vo := s.MustGetValidTypeObject(v)
+	x := s.mustGetOperand(v)
 	T := s.MustGetValidType(t)
 
-	vc, ok := vo.(*types.Const)
-	if !ok {
+	tb, ok := ToBasic(T)
+	if x.val == nil || !ok {
 		return false
 	}
+	return mustAnswer(s.oracle.Representable(s, Operand{x.typ, x.val}, tb))
+}
+
+func Representable(code, v, t string) bool {
+	s := cachedSpec(code)
+	return s.Representable(v, t)
+}
 
-	x := &operand{mode: constant_, typ: vo.Type(), val: vc.Val()}
+// RepresentableValue reports whether the constant x is representable by a value of type t.
+func RepresentableValue(x constant.Value, t *types.Basic) bool {
+	_, failure := representation(x, t, nil)
+	return failure == RepresentOK
+}
 
+// RepresentableE is like Representable, but returns an error instead of panicking,
+// such as a *NotFoundError if v or t is not found, or a *NotConstantError if v is not a constant.
+func (s *Spec) RepresentableE(v, t string) (bool, error) {
+	x, err := s.getOperand(v)
+	if err != nil {
+		return false, err
+	}
+	if x.val == nil {
+		return false, &NotConstantError{Expr: v}
+	}
+	T, err := s.getType(t)
+	if err != nil {
+		return false, err
+	}
 	tb, ok := ToBasic(T)
 	if !ok {
-		return false
+		return false, nil
+	}
+	return s.oracle.Representable(s, Operand{x.typ, x.val}, tb)
+}
+
+// RepresentableE is like Representable, but returns an error instead of panicking,
+// a *CheckError if code fails to type-check, see Spec.RepresentableE.
+func RepresentableE(code, v, t string) (bool, error) {
+	s, err := cachedSpecE(code)
+	if err != nil {
+		return false, err
+	}
+	return s.RepresentableE(v, t)
+}
+
+// Representation returns the value the constant v becomes in type t, such as 2.7182817 for 2.718281828459045 in float32,
+// or nil and the reason it is not representable.
+func (s *Spec) Representation(v, t string) (constant.Value, RepresentFailure) {
+	x := s.mustGetOperand(v)
+	T := s.MustGetValidType(t)
+
+	tb, ok := ToBasic(T)
+	if x.val == nil || !ok {
+		return nil, RepresentMismatch
+	}
+	return representation(x.val, tb, s.sizes)
+}
+
+// representation returns the value of the constant x in typ, or the reason it is not representable.
+func representation(x constant.Value, typ *types.Basic, sizes types.Sizes) (constant.Value, RepresentFailure) {
+	rounded := x
+	if representableConst(x, typ, sizes, &rounded) {
+		return rounded, RepresentOK
+	}
+	switch k := x.Kind(); {
+	case !IsNumeric(typ) || k != constant.Int && k != constant.Float && k != constant.Complex:
+		return nil, RepresentMismatch
+	case !IsComplex(typ) && constant.Sign(constant.Imag(x)) != 0:
+		return nil, RepresentTruncated
+	case IsInteger(typ) && constant.ToInt(x).Kind() != constant.Int:
+		return nil, RepresentNotInteger
+	default:
+		return nil, RepresentOverflow
+	}
+}
+
+// stdSizes are the sizes go/types uses if types.Config.Sizes is nil.
+var stdSizes = types.SizesFor("gc", "amd64")
+
+// representableConst reports whether the constant x is representable by a value of the basic type typ,
+// see go/types.representableConst. sizes tells the size of int, uint and uintptr, stdSizes is used if it is nil.
+// If rounded is not nil, it is set to the rounded value of x for floating-point and complex types,
+// and to an Int value for integer types.
+func representableConst(x constant.Value, typ *types.Basic, sizes types.Sizes, rounded *constant.Value) bool {
+	if x.Kind() == constant.Unknown {
+		return true // as go/types, avoid follow-up errors
 	}
+	if sizes == nil {
+		sizes = stdSizes
+	}
+
+	switch {
+	case IsInteger(typ):
+		x := constant.ToInt(x)
+		if x.Kind() != constant.Int {
+			return false
+		}
+		if rounded != nil {
+			*rounded = x
+		}
+		if x, ok := constant.Int64Val(x); ok {
+			switch typ.Kind() {
+			case types.Int:
+				var s = uint(sizes.Sizeof(typ)) * 8
+				return int64(-1)<<(s-1) <= x && x <= int64(1)<<(s-1)-1
+			case types.Int8:
+				return math.MinInt8 <= x && x <= math.MaxInt8
+			case types.Int16:
+				return math.MinInt16 <= x && x <= math.MaxInt16
+			case types.Int32:
+				return math.MinInt32 <= x && x <= math.MaxInt32
+			case types.Int64, types.UntypedInt:
+				return true
+			case types.Uint, types.Uintptr:
+				if s := uint(sizes.Sizeof(typ)) * 8; s < 64 {
+					return 0 <= x && x <= int64(1)<<s-1
+				}
+				return 0 <= x
+			case types.Uint8:
+				return 0 <= x && x <= math.MaxUint8
+			case types.Uint16:
+				return 0 <= x && x <= math.MaxUint16
+			case types.Uint32:
+				return 0 <= x && x <= math.MaxUint32
+			case types.Uint64:
+				return 0 <= x
+			default:
+				panic("unreachable")
+			}
+		}
+		// x does not fit into int64
+		switch n := constant.BitLen(x); typ.Kind() {
+		case types.Uint, types.Uintptr:
+			var s = uint(sizes.Sizeof(typ)) * 8
+			return constant.Sign(x) >= 0 && n <= int(s)
+		case types.Uint64:
+			return constant.Sign(x) >= 0 && n <= 64
+		case types.UntypedInt:
+			return true
+		}
+
+	case IsFloat(typ):
+		x := constant.ToFloat(x)
+		if x.Kind() != constant.Float {
+			return false
+		}
+		switch typ.Kind() {
+		case types.Float32:
+			if r := roundFloat32(x); r != nil {
+				if rounded != nil {
+					*rounded = r
+				}
+				return true
+			}
+		case types.Float64:
+			if r := roundFloat64(x); r != nil {
+				if rounded != nil {
+					*rounded = r
+				}
+				return true
+			}
+		case types.UntypedFloat:
+			return true
+		default:
+			panic("unreachable")
+		}
 
-	_representable(s.checker, x, tb)
-	if x.mode > 0 {
-		return true
+	case IsComplex(typ):
+		x := constant.ToComplex(x)
+		if x.Kind() != constant.Complex {
+			return false
+		}
+		round := roundFloat64
+		switch typ.Kind() {
+		case types.Complex64:
+			round = roundFloat32
+		case types.Complex128:
+		case types.UntypedComplex:
+			return true
+		default:
+			panic("unreachable")
+		}
+		re, im := round(constant.Real(x)), round(constant.Imag(x))
+		if re != nil && im != nil {
+			if rounded != nil {
+				*rounded = constant.BinaryOp(re, token.ADD, constant.MakeImag(im))
+			}
+			return true
+		}
+
+	case IsString(typ):
+		return x.Kind() == constant.String
+
+	case IsBoolean(typ):
+		return x.Kind() == constant.Bool
 	}
+
 	return false
 }
 
-func Representable(code, v, t string) bool {
-	s := NewSpec(code)
-	return s.Representable(v, t)
+// roundFloat32 returns x rounded to float32, or nil if it overflows.
+func roundFloat32(x constant.Value) constant.Value {
+	f32, _ := constant.Float32Val(x)
+	if f := float64(f32); !math.IsInf(f, 0) {
+		return constant.MakeFloat64(f)
+	}
+	return nil
+}
+
+// roundFloat64 returns x rounded to float64, or nil if it overflows.
+func roundFloat64(x constant.Value) constant.Value {
+	if f, _ := constant.Float64Val(x); !math.IsInf(f, 0) {
+		return constant.MakeFloat64(f)
+	}
+	return nil
 }
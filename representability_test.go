@@ -2,6 +2,8 @@ package gospec
 
 import (
 	"fmt"
+	"go/constant"
+	"go/types"
 	"testing"
 )
 
@@ -104,3 +106,84 @@ func TestRepresentable03(t *testing.T) {
 		t.Error(`test failed`)
 	}
 }
+
+// func (s *Spec) Representation(v, t string) (constant.Value, RepresentFailure)
+func TestSpec_Representation(t *testing.T) {
+	s := NewSpec(`type T int8; var v int`)
+	infos := []struct {
+		x, T    string
+		value   string
+		failure RepresentFailure
+	}{
+		{`2.718281828459045`, `float32`, `2.7182817`, RepresentOK},
+		{`-1e-1000`, `float64`, `0`, RepresentOK},
+		{`42.0`, `byte`, `42`, RepresentOK},
+		{`(42 + 0i)`, `float32`, `42`, RepresentOK},
+		{`'a'`, `T`, `97`, RepresentOK},
+		{`1024`, `byte`, ``, RepresentOverflow},
+		{`-1`, `uint16`, ``, RepresentOverflow},
+		{`1e1000`, `float64`, ``, RepresentOverflow},
+		{`42i`, `float32`, ``, RepresentTruncated},
+		{`1 + 2i`, `int`, ``, RepresentTruncated},
+		{`1.1`, `int`, ``, RepresentNotInteger},
+		{`0`, `bool`, ``, RepresentMismatch},
+		{`'a'`, `string`, ``, RepresentMismatch},
+		{`v`, `int`, ``, RepresentMismatch},
+		{`1`, `[]int`, ``, RepresentMismatch},
+	}
+	for _, v := range infos {
+		val, failure := s.Representation(v.x, v.T)
+		if failure != v.failure || (val == nil) != (v.value == "") {
+			t.Errorf("Representation(%s, %s) is %v, %v", v.x, v.T, val, failure)
+			continue
+		}
+		if val != nil {
+			f, _ := constant.Float32Val(val)
+			if fmt.Sprint(f) != v.value {
+				t.Errorf("Representation(%s, %s) is %v, %v", v.x, v.T, val, failure)
+			}
+		}
+	}
+	if RepresentNotInteger.String() != "not an integer" || RepresentFailure(-1).String() != "invalid" || RepresentFailure(100).String() != "invalid" {
+		t.Error(`test failed`)
+	}
+}
+
+// the rules engine must agree with go/types.(*Checker).representable, the rounded values too
+func TestRepresentableMatchesChecker(t *testing.T) {
+	values := []string{`'a'`, `97`, `"foo"`, `1024`, `42.0`, `1e10`, `2.718281828459045`, `-1e-1000`, `0i`, `(42 + 0i)`,
+		`0`, `-1`, `1.1`, `42i`, `1e1000`, `1 << 64`, `-1 << 63`, `1 << 63`, `true`, `1e-50`, `3.4e38`, `3.5e38`}
+	typs := []string{`bool`, `string`, `int`, `int8`, `int16`, `int32`, `int64`, `uint`, `uint8`, `uint16`, `uint32`,
+		`uint64`, `uintptr`, `float32`, `float64`, `complex64`, `complex128`, `rune`, `byte`}
+	for _, sizes := range []string{"amd64", "386"} {
+		s := NewSpec(``, WithSizes(types.SizesFor("gc", sizes)))
+		for _, v := range values {
+			for _, typ := range typs {
+				tb, _ := ToBasic(s.MustGetValidType(typ))
+				wantVal, want, err := linkRepresentable(s.checker, s.mustGetOperand(v), tb)
+				if err != nil {
+					t.Fatal(err)
+				}
+				val, failure := s.Representation(v, typ)
+				if (failure == RepresentOK) != want {
+					t.Errorf("%s: Representable(%s, %s) should be %v", sizes, v, typ, want)
+				} else if want && wantVal.ExactString() != val.ExactString() {
+					t.Errorf("%s: Representation(%s, %s) should be %v, not %v", sizes, v, typ, wantVal, val)
+				}
+			}
+		}
+	}
+}
+
+// func RepresentableValue(x constant.Value, t *types.Basic) bool
+func TestRepresentableValue(t *testing.T) {
+	if !RepresentableValue(constant.MakeInt64(255), types.Typ[types.Uint8]) || RepresentableValue(constant.MakeInt64(256), types.Typ[types.Uint8]) {
+		t.Error(`test failed`)
+	}
+	if !RepresentableValue(constant.MakeFloat64(1.5), types.Typ[types.Float32]) || RepresentableValue(constant.MakeFloat64(1.5), types.Typ[types.Int]) {
+		t.Error(`test failed`)
+	}
+	if RepresentableValue(constant.MakeString("a"), types.Typ[types.Int]) || !RepresentableValue(constant.MakeBool(true), types.Typ[types.UntypedBool]) {
+		t.Error(`test failed`)
+	}
+}
@@ -1,20 +1,16 @@
 package gospec
 
 import (
+	"fmt"
 	"go/ast"
-	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"log"
+	"strings"
+	"sync"
 )
 
-func init() {
-	spec = NewSpec("")
-}
-
-var spec *Spec
-
 type SearchKind int
 
 // src/go/types/scope.go Scope has 4 level：Universe、Package、File、Local
@@ -22,58 +18,226 @@ const (
 	SearchOnlyPackage = iota
 	SearchPackageAndUniverse
 	SearchAll
+	// SearchAllStrict is like SearchAll, but a name declared in several scopes of the code is not found,
+	// LookupStrict tells the candidates.
+	SearchAllStrict
 )
 
+// Spec answers queries about the types of the code it checked.
+// Once it is created, a Spec is safe for concurrent use by multiple goroutines:
+// the queries which run a type checker, such as evaluating expressions, are serialized.
 type Spec struct {
-	code    string
-	file    *ast.File
-	pkg     *types.Package
-	checker *types.Checker
+	mu        sync.Mutex // serializes the queries which run a type checker, or import packages
+	code      string
+	sources   []*sourceFile
+	fset      *token.FileSet
+	files     []*ast.File
+	pkg       *types.Package
+	importer  types.Importer
+	checker   *types.Checker
+	info      *types.Info
+	errors    []types.Error
+	sizes     types.Sizes // the sizes of the checker, nil for the default
+	goVersion string      // the Go version of the checker, "" for the latest
+	oracle    RelationOracle
+	// locals are the scopes of statements wrapped by normalizeCode, whose names are looked up as top-level names
+	locals []*types.Scope
 	SearchKind
 }
 
-func NewSpec(code string) *Spec {
-	s := new(Spec)
-	addPackageHeadToCode(&code)
-	packageName := mustGetPackageNameFromCode(code)
-	s.code = code
+// sourceFile is a file of code to check
+type sourceFile struct {
+	name string
+	code string // the normalized code, see normalizeCode
+	orig string // the code as it was given
+	head header // the header inserted into orig
+}
 
-	var err error
-	fset := token.NewFileSet()
-	s.file, err = parser.ParseFile(fset, packageName+".go", code, 0)
-	if err != nil {
-		log.Panicf("parse code failed: %s", err)
+// newSourceFile normalizes code, it returns the file and the name of its package.
+// packageName is the package name of code without package clause.
+func newSourceFile(name, code, packageName string) (*sourceFile, string) {
+	normalized, packageName, head := normalizeCode(code, packageName)
+	return &sourceFile{name: name, code: normalized, orig: code, head: head}, packageName
+}
+
+// originalPosition maps pos in the normalized code back into the original code.
+// A position in the inserted header is reported without offset, line and column.
+func (src *sourceFile) originalPosition(pos token.Position) token.Position {
+	offset := pos.Offset
+	if offset >= src.head.offset {
+		offset -= len(src.head.text)
+		if offset < src.head.offset || offset > len(src.orig) {
+			pos.Offset, pos.Line, pos.Column = 0, 0, 0
+			return pos
+		}
 	}
-	c := new(types.Config)
-	c.Error = func(err error) {}    // 防止触发 go/types.(*Checker).err 方法里的 panic
-	c.Importer = importer.Default() // 增加golang包导入，使之可以识别 import 的包
-	s.pkg = types.NewPackage(packageName, "")
-	s.checker = types.NewChecker(c, fset, s.pkg, nil)
-	s.SearchKind = SearchPackageAndUniverse // default search in universe and pkg scope
+	pos.Offset = offset
+	pos.Line = strings.Count(src.orig[:offset], "\n") + 1
+	pos.Column = offset - lineStart(src.orig, offset) + 1
+	return pos
+}
+
+// offsetOf returns the offset in the normalized code of line and column col of the original code,
+// ok is false if there is no such line.
+func (src *sourceFile) offsetOf(line, col int) (offset int, ok bool) {
+	if line < 1 || col < 1 {
+		return 0, false
+	}
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(src.orig[offset:], '\n')
+		if next < 0 {
+			return 0, false
+		}
+		offset += next + 1
+	}
+	offset += col - 1
+	if offset > len(src.orig) {
+		return 0, false
+	}
+	if offset >= src.head.offset {
+		offset += len(src.head.text)
+	}
+	return offset, true
+}
 
-	err = s.checker.Files([]*ast.File{s.file})
+// NewSpec checks code, which may omit its package clause, and panics if it can not be parsed or type-checked,
+// use NewSpecE to get the error instead. The Spec is configured by opts, such as WithGoVersion("go1.17").
+// Code without package clause may also be an expression, or statements, which are wrapped in a function.
+// The locals of the statements are found as top-level names, and they are not reported as declared and not used.
+func NewSpec(code string, opts ...Option) *Spec {
+	s, err := NewSpecE(code, opts...)
 	if err != nil {
-		log.Panicf("check file failed: %s", err)
+		log.Panic(err)
 	}
 	return s
 }
 
+// NewSpecE is like NewSpec, but returns an error instead of panicking.
+// If code is parsed but fails to type-check, the returned error is a *CheckError,
+// and the returned Spec is still usable: it keeps every error the checker reported.
+func NewSpecE(code string, opts ...Option) (*Spec, error) {
+	cfg := newConfig(opts)
+	src, packageName := newSourceFile("", code, cfg.packageName)
+	src.name = packageName + ".go"
+	return newSpec(cfg.pathOf(packageName), packageName, []*sourceFile{src}, cfg)
+}
+
+func newSpec(packagePath, packageName string, sources []*sourceFile, cfg *config) (*Spec, error) {
+	s := new(Spec)
+	s.sources = sources
+	codes := make([]string, len(sources))
+	for i, src := range sources {
+		codes[i] = src.code
+	}
+	s.code = strings.Join(codes, "\n")
+
+	if cfg.importer == nil {
+		cfg.importer = GetImporter(ImportAuto)
+	}
+	s.fset = token.NewFileSet()
+	if mi, ok := cfg.importer.(*memImporter); ok {
+		s.fset = mi.fset
+		mi.onError = s.addError
+		mi.onCheck = func(sources []*sourceFile) { s.sources = append(s.sources, sources...) }
+	}
+	for _, src := range sources {
+		f, err := parser.ParseFile(s.fset, src.name, src.code, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parse code failed: %w", err)
+		}
+		s.files = append(s.files, f)
+	}
+	c := new(types.Config)
+	c.Error = s.addError // 收集所有错误，同时防止触发 go/types.(*Checker).err 方法里的 panic
+	s.importer = cfg.importer
+	c.Importer = cfg.importer // 增加golang包导入，使之可以识别 import 的包
+	c.GoVersion = cfg.goVersion
+	c.Sizes = cfg.sizes
+	s.sizes = cfg.sizes
+	s.goVersion = cfg.goVersion
+	s.oracle = cfg.oracle
+	if s.oracle == nil {
+		s.oracle = RulesOracle
+	}
+	s.pkg = types.NewPackage(packagePath, packageName)
+	s.info = &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+	}
+	s.checker = types.NewChecker(c, s.fset, s.pkg, s.info)
+	s.SearchKind = cfg.searchKind
+
+	// errors of imported packages held in memory are not returned by Files, but collected by addError as well
+	err := s.checker.Files(s.files)
+	// the checker is used by queries later, their errors are not errors of the code
+	c.Error = func(err error) {}
+	for i, src := range sources {
+		if src.head.stmts {
+			wrapper := s.files[i].Decls[0].(*ast.FuncDecl)
+			if f, ok := s.info.Defs[wrapper.Name].(*types.Func); ok && f.Scope() != nil {
+				s.locals = append(s.locals, f.Scope())
+			}
+		}
+	}
+	if err != nil || len(s.errors) > 0 {
+		return s, &CheckError{Errors: s.Errors()}
+	}
+	return s, nil
+}
+
+// scratchFileSet returns a new FileSet with the files of s, for the code which is checked with the code of s
+// by a query, so the files of the query are dropped with it, instead of growing the FileSet of s.
+func (s *Spec) scratchFileSet() *token.FileSet {
+	fset := token.NewFileSet()
+	s.fset.Iterate(func(f *token.File) bool {
+		fset.AddFile(f.Name(), f.Base(), f.Size()).SetLines(f.Lines())
+		return true
+	})
+	return fset
+}
+
+func (s *Spec) addError(err error) {
+	if e, ok := err.(types.Error); ok {
+		s.errors = append(s.errors, e)
+	}
+}
+
+// Errors returns every error reported by the type checker, in the order they were reported.
+func (s *Spec) Errors() []types.Error {
+	// the errors of packages imported by probes are added later
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errs := make([]types.Error, len(s.errors))
+	copy(errs, s.errors)
+	return errs
+}
+
+// GetTypeObject finds the object named v by SearchKind.
+// v may also be a path, such as "fmt.Stringer", "T.Method", "T.field", "main.b" or "main/c",
+// which is looked up from the package, file and universe scopes whatever SearchKind is.
 func (s *Spec) GetTypeObject(v string) types.Object {
+	if o := s.lookupPath(v); o != nil {
+		return o
+	}
 	switch s.SearchKind {
 	case SearchOnlyPackage:
-		return s.pkg.Scope().Lookup(v)
+		return s.lookupPackage(v)
 	case SearchPackageAndUniverse:
-		o := s.pkg.Scope().Lookup(v)
+		o := s.lookupPackage(v)
 		if o == nil {
-			o = types.Universe.Lookup(v)
+			o = s.lookupUniverse(v)
 		}
 		return o
 	case SearchAll:
 		o := lookupByBFS(s.pkg.Scope(), v)
 		if o == nil {
-			o = types.Universe.Lookup(v)
+			o = s.lookupUniverse(v)
 		}
 		return o
+	case SearchAllStrict:
+		o, _ := s.LookupStrict(v)
+		return o
 	default:
 		panic("unexpect")
 	}
@@ -83,26 +247,63 @@ func (s *Spec) GetTypeObject(v string) types.Object {
 func (s *Spec) MustGetValidTypeObject(v string) types.Object {
 	o := s.GetTypeObject(v)
 	if o == nil {
-		panic("find <" + v + "> in code <" + s.code + "> failed")
+		s.panicNotFound(v)
 	}
 	return o
 }
 
+// panicNotFound panics with the reason v is not found.
+func (s *Spec) panicNotFound(v string) {
+	panic(s.notFound(v).Error())
+}
+
+// notFound returns the reason v is not found, an *AmbiguityError or a *NotFoundError.
+func (s *Spec) notFound(v string) error {
+	if s.SearchKind == SearchAllStrict {
+		if _, err := s.LookupStrict(v); err != nil {
+			return err
+		}
+	}
+	return &NotFoundError{Name: v, Code: s.code}
+}
+
+// GetType returns the type of the object named v, see GetTypeObject.
+// If v is not a name, it is evaluated as a type expression, such as "[]map[string]*T".
 func (s *Spec) GetType(v string) types.Type {
 	o := s.GetTypeObject(v)
 	if o == nil {
-		return nil
+		if token.IsIdentifier(v) {
+			return nil
+		}
+		return s.evalType(v)
 	} else {
 		return o.Type()
 	}
 }
 
 func (s *Spec) MustGetValidType(v string) types.Type {
-	o := s.GetTypeObject(v)
-	if o == nil {
-		panic("find <" + v + "> in code <" + s.code + "> failed")
+	t := s.GetType(v)
+	if t == nil {
+		s.panicNotFound(v)
+	}
+	return t
+}
+
+// getType is like MustGetValidType, but returns an error instead of panicking,
+// a *NotTypeError if v is an expression of a value.
+func (s *Spec) getType(v string) (types.Type, error) {
+	if o := s.GetTypeObject(v); o != nil {
+		return o.Type(), nil
+	}
+	if !token.IsIdentifier(v) {
+		if tv, err := s.eval(v); err == nil {
+			if !tv.IsType() {
+				return nil, &NotTypeError{Expr: v}
+			}
+			return tv.Type, nil
+		}
 	}
-	return o.Type()
+	return nil, s.notFound(v)
 }
 
 func (s *Spec) GetUnderlyingType(v string) types.Type {
@@ -128,14 +329,14 @@ func (s *Spec) GetBaseType(v string) types.Type {
 func (s *Spec) IsInUniverse(v string) bool {
 	switch s.SearchKind {
 	case SearchOnlyPackage, SearchPackageAndUniverse:
-		if s.pkg.Scope().Lookup(v) == nil {
-			if types.Universe.Lookup(v) != nil {
+		if s.lookupPackage(v) == nil {
+			if s.lookupUniverse(v) != nil {
 				return true
 			}
 		}
-	case SearchAll:
+	case SearchAll, SearchAllStrict:
 		if lookupByBFS(s.pkg.Scope(), v) == nil {
-			if types.Universe.Lookup(v) != nil {
+			if s.lookupUniverse(v) != nil {
 				return true
 			}
 		}
@@ -145,6 +346,19 @@ func (s *Spec) IsInUniverse(v string) bool {
 	return false
 }
 
+// lookupPackage finds v in the package scope, and then in the scopes of wrapped statements.
+func (s *Spec) lookupPackage(v string) types.Object {
+	if o := s.pkg.Scope().Lookup(v); o != nil {
+		return o
+	}
+	for _, scope := range s.locals {
+		if o := scope.Lookup(v); o != nil {
+			return o
+		}
+	}
+	return nil
+}
+
 func lookupByBFS(scope *types.Scope, v string) types.Object {
 	o := scope.Lookup(v)
 	if o != nil {
@@ -2,6 +2,8 @@ package gospec
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -263,3 +265,137 @@ func main() {
 		t.Error(`test failed`)
 	}
 }
+
+// func NewSpecE(code string) (*Spec, error)
+func TestNewSpecE(t *testing.T) {
+	if _, err := NewSpecE(`var a int = `); err == nil || !strings.HasPrefix(err.Error(), "parse code failed: ") {
+		t.Error(`test failed`)
+	}
+
+	s, err := NewSpecE(`
+func main() {
+	var a int = "x"
+	b := 1
+	_ = a
+}`)
+	checkErr, ok := err.(*CheckError)
+	if !ok || s == nil {
+		t.Fatal(`test failed`)
+	}
+	if len(checkErr.Errors) != 2 || len(s.Errors()) != 2 || !checkErr.HasHardErrors() {
+		t.Error(`test failed`)
+	}
+	if checkErr.Errors[0].Soft || !checkErr.Errors[1].Soft {
+		t.Error(`test failed`)
+	}
+	if s.GetTypeObject("main") == nil {
+		t.Error(`test failed`)
+	}
+
+	s, err = NewSpecE(`type T int`)
+	if err != nil || s == nil || len(s.Errors()) != 0 {
+		t.Error(`test failed`)
+	}
+}
+
+// statements and expressions are wrapped by NewSpec
+func TestNewSpec_snippet(t *testing.T) {
+	s, err := NewSpecE(`var s uint = 2
+type T int64
+x := T(1) << s
+var y, _ = x, 1`)
+	if err != nil {
+		t.Fatal(`test failed`)
+	}
+	if s.GetTypeObject("x") == nil || s.MustGetValidType("x").String() != "example.T" ||
+		s.GetTypeObject("T") == nil || s.GetTypeObject("y") == nil || s.IsInUniverse("x") {
+		t.Error(`test failed`)
+	}
+	if !s.Assignment("x", "T") || s.Assignment("x", "int64") || s.TypeOf("y + 1").String() != "example.T" {
+		t.Error(`test failed`)
+	}
+
+	s, err = NewSpecE(`float32(0.49999999)`)
+	if err != nil || s.ConstValue(`float32(0.49999999)`).String() != "0.5" {
+		t.Error(`test failed`)
+	}
+
+	// positions are still the ones of the snippet
+	s, err = NewSpecE(`x := 1
+var y string = x`)
+	ds := s.Diagnostics()
+	if err == nil || len(ds) != 1 || ds[0].Pos.Line != 2 || ds[0].Pos.Column != 16 {
+		t.Error(`test failed`)
+	}
+	if o := s.LookupAt("x", 2, 16); o == nil || s.GetTypeObject("x") != o {
+		t.Error(`test failed`)
+	}
+}
+
+// queries on one Spec run concurrently, go test -race tells their races
+func TestSpec_concurrent(t *testing.T) {
+	for _, o := range []RelationOracle{RulesOracle, LinknameOracle, ProbeOracle} {
+		s := NewSpec(`import "fmt"; type T int; const c = 1 << 10; var x T; var p fmt.Stringer`, WithOracle(o))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if !s.Assignment("c", "T") || s.Assignment("x", "int") || !s.Conversion("x", "int") ||
+					!s.Representable("c", "int16") || s.Representable("c", "int8") ||
+					!s.Identical("T", "T") || !s.Comparable("T") || !s.Implements("p", "fmt.Stringer") {
+					t.Error(`test failed`)
+				}
+				if s.TypeOf("x + 1") != s.GetType("T") || s.ConstValue("c * 2").String() != "2048" || s.Mode("x") != ModeVariable {
+					t.Error(`test failed`)
+				}
+				if ok, _, err := s.ProbeAssignment("p", "interface{ String() string }"); err != nil || !ok {
+					t.Error(`test failed`)
+				}
+				if len(s.Errors()) != 0 || len(s.Diagnostics()) != 0 || !s.Compiles() {
+					t.Error(`test failed`)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// function literals are checked while their names are looked up
+	s := NewSpec(`var x int`, WithSearchKind(SearchAll))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 0 {
+				if typ := s.TypeOf("func(q int) int { return q }"); typ == nil || typ.String() != "func(q int) int" {
+					t.Error(`test failed`)
+				}
+				return
+			}
+			if s.GetTypeObject("x") == nil || len(s.LookupAll("q")) != 0 {
+				t.Error(`test failed`)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// queries check code of their own, which does not grow the FileSet of the Spec
+func TestSpec_queriesKeepFileSet(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithGoVersion("go1.20")}, {WithOracle(ProbeOracle)}} {
+		s := NewSpec(`import "fmt"; type T int; const c = 1 << 10; var p fmt.Stringer; var x = T(1)`, opts...)
+		base := s.fset.Base()
+		for i := 0; i < 10; i++ {
+			if !s.Assignment("c + 1", "T") || s.TypeOf("x + 1") != s.GetType("T") || !s.Implements("p", "fmt.Stringer") {
+				t.Error(`test failed`)
+			}
+			if ok, _, err := s.ProbeConversion("x", "float64"); err != nil || !ok {
+				t.Error(`test failed`)
+			}
+		}
+		if s.fset.Base() != base {
+			t.Error(`test failed`)
+		}
+	}
+}
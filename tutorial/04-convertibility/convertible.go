@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	gospec "github.com/AlaxLee/go-spec-util"
 )
@@ -27,61 +26,52 @@ func convertibleExample01() {
 	int(1.2)                 // illegal: 1.2 cannot be represented as an int
 	string(65.0)             // illegal: 65.0 is not an integer constant
 	*/
-	type Info struct {
-		x string
-		T string
+	// a snippet may be a declaration, statements or just the expression
+	snippets := []struct {
+		code string
+		expr string
+	}{
+		{`const x = uint(iota)`, `x`},
+		{`float32(2.718281828)`, ``},
+		{`complex128(1)`, ``},
+		{`float32(0.49999999)`, ``},
+		{`float64(-1e-1000)`, ``},
+		{`string('x')`, ``},
+		{`string(0x266c)`, ``},
+		{`type MyString string; x := MyString("foo" + "bar")`, `MyString("foo" + "bar")`},
+		{`string([]byte{'a'})`, ``},
+		{`(*int)(nil)`, ``},
+		{`int(1.2)`, ``},
+		{`string(65.0)`, ``},
 	}
 
-	infos := []Info{
-		{`iota`, `uint`},
-		{`2.718281828`, `float32`},
-		{`1`, `complex128`},
-		{`0.49999999`, `float32`},
-		{`-1e-1000`, `float64`},
-		{`'x'`, `string`},
-		{`0x266c`, `string`},
-		{`"foo" + "bar"`, `MyString; type MyString string`},
-		{`[]byte{'a'}`, `string`},
-		{`nil`, `(*int)`},
-		{`1.2`, `int`},
-		{`65.0`, `string`},
-	}
-
-	newSpec := func(code string) (s *gospec.Spec, e error) {
-		defer func() {
-			if r := recover(); r != nil {
-				e = errors.New(fmt.Sprintf("%s", r))
-			}
-		}()
-		s = gospec.NewSpec(code)
-		return
-	}
-
-	for _, v := range infos {
-		code := fmt.Sprintf("type T %s; const x = %s", v.T, v.x)
-		s, err := newSpec(code)
+	for _, v := range snippets {
+		expr := v.expr
+		if expr == "" {
+			expr = v.code
+		}
+		s, err := gospec.NewSpecE(v.code)
 		if err != nil {
-			//fmt.Printf("%12s could not convert to type %s, because %s\n", v.x, v.T, err)
-			fmt.Printf("%20s could not convert to type %s\n", v.x, v.T)
-		} else if s.Conversion("x", "T") {
-			fmt.Printf("%20s could convert to type %s\n", v.x, v.T)
+			fmt.Printf("%50s is illegal\n", v.code)
+		} else if c := s.ConstValue(expr); c != nil {
+			fmt.Printf("%50s is %s of type %s\n", v.code, c, s.TypeOf(expr))
 		} else {
-			fmt.Printf("%20s could not convert to type %s\n", v.x, v.T)
+			fmt.Printf("%50s is not a constant\n", v.code)
 		}
 	}
 	/* the output is:
-	            iota could convert to type uint
-	     2.718281828 could convert to type float32
-	               1 could convert to type complex128
-	      0.49999999 could convert to type float32
-	        -1e-1000 could convert to type float64
-	             'x' could convert to type string
-	          0x266c could convert to type string
-	   "foo" + "bar" could convert to type MyString; type MyString string
-	     []byte{'a'} could not convert to type string
-	             nil could not convert to type (*int)
-	             1.2 could not convert to type int
-	            65.0 could not convert to type string
+	                              const x = uint(iota) is 0 of type uint
+	                              float32(2.718281828) is 2.71828 of type float32
+	                                     complex128(1) is (1 + 0i) of type complex128
+	                               float32(0.49999999) is 0.5 of type float32
+	                                 float64(-1e-1000) is 0 of type float64
+	                                       string('x') is "x" of type string
+	                                    string(0x266c) is "♬" of type string
+	type MyString string; x := MyString("foo" + "bar") is "foobar" of type example.MyString
+	                               string([]byte{'a'}) is not a constant
+	                                       (*int)(nil) is not a constant
+	                                          int(1.2) is illegal
+	                                      string(65.0) is illegal
 	*/
 }
 
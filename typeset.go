@@ -0,0 +1,97 @@
+package gospec
+
+import "go/types"
+
+// typeSetIs calls f with each specific type in the type set of tp, it reports whether all calls return true.
+// If tp has no specific types, f is called with nil, as go/types does.
+func typeSetIs(tp *types.TypeParam, f func(*types.Term) bool) bool {
+	terms, all := termsOf(tp.Constraint())
+	if all || len(terms) == 0 {
+		return f(nil)
+	}
+	for _, t := range terms {
+		if !f(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// underIs calls f with the underlying type of t, or of each specific type in the type set of t if t is a type parameter,
+// it reports whether all calls return true.
+func underIs(t types.Type, f func(types.Type) bool) bool {
+	if tp, ok := types.Unalias(t).(*types.TypeParam); ok {
+		return typeSetIs(tp, func(term *types.Term) bool {
+			if term == nil {
+				return f(nil)
+			}
+			return f(term.Type().Underlying())
+		})
+	}
+	return f(t.Underlying())
+}
+
+// termsOf returns the specific types of the type set of the constraint t, all is true if it has no specific types.
+// Methods and comparable are not taken into account.
+func termsOf(t types.Type) (terms []*types.Term, all bool) {
+	iface, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return []*types.Term{types.NewTerm(false, t)}, false
+	}
+	all = true
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		var ts []*types.Term
+		var tsAll bool
+		if u, ok := iface.EmbeddedType(i).(*types.Union); ok {
+			ts, tsAll = termsOfUnion(u)
+		} else {
+			ts, tsAll = termsOf(iface.EmbeddedType(i))
+		}
+		switch {
+		case tsAll:
+		case all:
+			terms, all = ts, false
+		default:
+			terms = intersectTerms(terms, ts)
+		}
+	}
+	return terms, all
+}
+
+func termsOfUnion(u *types.Union) (terms []*types.Term, all bool) {
+	for i := 0; i < u.Len(); i++ {
+		term := u.Term(i)
+		if _, ok := term.Type().Underlying().(*types.Interface); !ok {
+			terms = append(terms, term)
+			continue
+		}
+		ts, tsAll := termsOf(term.Type())
+		if tsAll {
+			return nil, true
+		}
+		terms = append(terms, ts...)
+	}
+	return terms, false
+}
+
+func intersectTerms(a, b []*types.Term) []*types.Term {
+	var terms []*types.Term
+	for _, x := range a {
+		for _, y := range b {
+			if includesTerm(y, x) {
+				terms = append(terms, x)
+			} else if includesTerm(x, y) {
+				terms = append(terms, y)
+			}
+		}
+	}
+	return terms
+}
+
+// includesTerm reports whether the type set of x is a subset of the one of y.
+func includesTerm(y, x *types.Term) bool {
+	if y.Tilde() {
+		return types.Identical(x.Type().Underlying(), y.Type())
+	}
+	return !x.Tilde() && types.Identical(x.Type(), y.Type())
+}
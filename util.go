@@ -1,19 +1,149 @@
 package gospec
 
 import (
-	easyregexp "github.com/AlaxLee/easyregexp"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
 )
 
-func addPackageHeadToCode(codePtr *string) {
-	if !easyregexp.Match(`^\s*package\s+`, *codePtr) {
-		*codePtr = easyregexp.ReplaceAll(`^\s*`, *codePtr, "package example\n")
+const defaultPackageName = "example"
+
+// header is the text normalizeCode inserted at offset of the original code, and appended to it.
+type header struct {
+	offset int
+	text   string
+	tail   string
+	// stmts reports whether the code is statements wrapped in the body of an init function
+	stmts bool
+}
+
+func (h header) apply(code string) string {
+	return code[:h.offset] + h.text + code[h.offset:] + h.tail
+}
+
+// normalizeCode inserts the package clause of packageName into code if it has none.
+// Code without package clause may also be an expression, such as "float32(0.49999999)",
+// which is wrapped as "var _ = float32(0.49999999)", or statements, such as "x := 1 << s",
+// which are wrapped in the body of an init function, followed by "_ = x" for each local they declare.
+// It returns the normalized code, the name of its package, and the header it inserted (empty if none).
+func normalizeCode(code, packageName string) (string, string, header) {
+	name, hasClause, at := scanPackageClause(code)
+	if hasClause {
+		if name == "" { // not a valid package clause, left to the parser to report
+			name = packageName
+		}
+		return code, name, header{}
+	}
+	h := header{offset: at, text: "package " + packageName + "\n"}
+	if parses(h.apply(code)) {
+		return h.apply(code), packageName, h
+	}
+
+	if _, err := parser.ParseExpr(code); err == nil {
+		expr := header{offset: at, text: h.text + "var _ = ", tail: "\n"}
+		return expr.apply(code), packageName, expr
+	}
+	stmts := header{offset: at, text: h.text + "func init() {\n", tail: "\n}\n", stmts: true}
+	if f, err := parser.ParseFile(token.NewFileSet(), "", stmts.apply(code), 0); err == nil {
+		var uses strings.Builder
+		for _, name := range localsOf(f.Decls[0].(*ast.FuncDecl).Body) {
+			uses.WriteString("_ = " + name + "\n")
+		}
+		stmts.tail = "\n" + uses.String() + "}\n"
+		return stmts.apply(code), packageName, stmts
+	}
+	// not a snippet, left to the parser to report
+	return h.apply(code), packageName, h
+}
+
+func parses(code string) bool {
+	_, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	return err == nil
+}
+
+// localsOf returns the names of variables declared in the top level of body.
+func localsOf(body *ast.BlockStmt) []string {
+	var names []string
+	add := func(ident *ast.Ident) {
+		if ident.Name != "_" {
+			names = append(names, ident.Name)
+		}
 	}
+	for _, stmt := range body.List {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				continue
+			}
+			for _, lhs := range stmt.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					add(ident)
+				}
+			}
+		case *ast.DeclStmt:
+			if d, ok := stmt.Decl.(*ast.GenDecl); ok && d.Tok == token.VAR {
+				for _, spec := range d.Specs {
+					for _, ident := range spec.(*ast.ValueSpec).Names {
+						add(ident)
+					}
+				}
+			}
+		}
+	}
+	return names
 }
 
-func mustGetPackageNameFromCode(code string) string {
-	catches := easyregexp.Catch(`package\s+(\w+)`, code)
-	if len(catches) == 0 {
-		panic("get packageName failed")
+// scanPackageClause scans the tokens of code to find its package clause, and returns the package name.
+// If there is no package clause, it returns the offset where one should be inserted: the start of the line
+// of the first token, or of the comments attached to it (such as doc comments and //go: directives).
+// The leading comments which are separated from the first token by a blank line, or hold build constraints,
+// stay in front of the package clause.
+func scanPackageClause(code string) (name string, hasClause bool, insertAt int) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(code))
+	var sc scanner.Scanner
+	sc.Init(file, []byte(code), nil, scanner.ScanComments)
+
+	var group []string       // the last group of comments
+	var groupStart token.Pos // the position of the first comment of group
+	var groupEndLine int     // the line where the last comment of group ends
+	for {
+		pos, tok, lit := sc.Scan()
+		switch tok {
+		case token.COMMENT:
+			if len(group) == 0 || file.Line(pos) > groupEndLine+1 {
+				group, groupStart = nil, pos
+			}
+			group = append(group, lit)
+			groupEndLine = file.Line(pos) + strings.Count(lit, "\n")
+			continue
+		case token.PACKAGE:
+			if _, tok, lit := sc.Scan(); tok == token.IDENT {
+				return lit, true, 0
+			}
+			return "", true, 0
+		}
+
+		at := pos
+		if len(group) > 0 && groupEndLine+1 >= file.Line(pos) && !isBuildConstraint(group) {
+			at = groupStart
+		}
+		return "", false, lineStart(code, file.Offset(at))
 	}
-	return catches[0]
+}
+
+func isBuildConstraint(comments []string) bool {
+	for _, c := range comments {
+		if strings.HasPrefix(c, "//go:build") || strings.HasPrefix(c, "// +build") {
+			return true
+		}
+	}
+	return false
+}
+
+// lineStart returns the offset of the start of the line of offset in code.
+func lineStart(code string, offset int) int {
+	return strings.LastIndex(code[:offset], "\n") + 1
 }
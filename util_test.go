@@ -1,24 +1,78 @@
 package gospec
 
 import (
-	"strings"
+	"go/token"
 	"testing"
 )
 
-func Test_addPackageHeadToCode(t *testing.T) {
-	code := ` var a int`
-	addPackageHeadToCode(&code)
-	if !strings.HasPrefix(code, "package example\n") {
+func Test_normalizeCode(t *testing.T) {
+	code, name, head := normalizeCode(` var a int`, defaultPackageName)
+	if code != "package example\n var a int" || name != "example" || head.offset != 0 {
+		t.Error(`test failed`)
+	}
+	code, name, head = normalizeCode("\npackage haha\nvar a int", defaultPackageName)
+	if code != "\npackage haha\nvar a int" || name != "haha" || head.text != "" {
+		t.Error(`test failed`)
+	}
+
+	// a comment mentioning package is not a package clause
+	code, name, _ = normalizeCode("// package foo is not here\nvar a int", defaultPackageName)
+	if code != "package example\n// package foo is not here\nvar a int" || name != "example" {
+		t.Error(`test failed`)
+	}
+	code, name, _ = normalizeCode("/* package foo */ package bar\nvar a int", defaultPackageName)
+	if code != "/* package foo */ package bar\nvar a int" || name != "bar" {
+		t.Error(`test failed`)
+	}
+
+	// build constraints and detached comments stay in front of the package clause
+	code, _, head = normalizeCode("//go:build linux\n\n// Doc of a.\nvar a int", defaultPackageName)
+	if code != "//go:build linux\n\npackage example\n// Doc of a.\nvar a int" || head.offset != 18 {
+		t.Error(`test failed`)
+	}
+	code, _, _ = normalizeCode("// +build linux\nvar a int", defaultPackageName)
+	if code != "// +build linux\npackage example\nvar a int" {
+		t.Error(`test failed`)
+	}
+
+	// directives stay attached to their declaration
+	code, _, _ = normalizeCode("//go:noinline\nfunc f() {}", defaultPackageName)
+	if code != "package example\n//go:noinline\nfunc f() {}" {
 		t.Error(`test failed`)
 	}
 }
 
-func Test_mustGetPackageNameFromCode(t *testing.T) {
-	code := `
-package haha
-var a int`
-	pn := mustGetPackageNameFromCode(code)
-	if pn != "haha" {
+func Test_sourceFile(t *testing.T) {
+	src, _ := newSourceFile("a.go", "//go:build linux\n\nvar a int", defaultPackageName)
+	offset, ok := src.offsetOf(3, 5)
+	if !ok || src.code[offset:offset+1] != "a" {
+		t.Error(`test failed`)
+	}
+	pos := src.originalPosition(token.Position{Filename: "a.go", Offset: offset})
+	if pos.Line != 3 || pos.Column != 5 || pos.Offset != 22 {
+		t.Error(`test failed`)
+	}
+	pos = src.originalPosition(token.Position{Filename: "a.go", Offset: 20})
+	if pos.Line != 0 || pos.Column != 0 {
+		t.Error(`test failed`)
+	}
+	if _, ok := src.offsetOf(4, 1); ok {
+		t.Error(`test failed`)
+	}
+}
+
+func Test_normalizeCode_snippet(t *testing.T) {
+	code, _, head := normalizeCode(`float32(0.49999999)`, defaultPackageName)
+	if code != "package example\nvar _ = float32(0.49999999)\n" || head.stmts {
+		t.Error(`test failed`)
+	}
+	code, _, head = normalizeCode("x, _ := 1, 2\nvar y int", defaultPackageName)
+	if code != "package example\nfunc init() {\nx, _ := 1, 2\nvar y int\n_ = x\n_ = y\n}\n" || !head.stmts {
+		t.Error(`test failed`)
+	}
+	// declarations are not wrapped, even if they fail to parse
+	code, _, head = normalizeCode(`var a int = `, defaultPackageName)
+	if code != "package example\nvar a int = " || head.tail != "" {
 		t.Error(`test failed`)
 	}
 }
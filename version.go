@@ -0,0 +1,77 @@
+package gospec
+
+import (
+	"go/types"
+	"go/version"
+	"strings"
+)
+
+// normalizeGoVersion adds the prefix "go" to version if it has not, so "1.17" is "go1.17".
+func normalizeGoVersion(v string) string {
+	if v != "" && !strings.HasPrefix(v, "go") {
+		v = "go" + v
+	}
+	return v
+}
+
+// allowVersion reports whether the features of the Go version v are allowed in goVersion, "" allows all of them.
+func allowVersion(goVersion, v string) bool {
+	return goVersion == "" || version.Compare(goVersion, v) >= 0
+}
+
+// universeVersions are the Go versions which added names to the universe scope, the others are in every version.
+var universeVersions = map[string]string{
+	"any":        "go1.18",
+	"comparable": "go1.18",
+	"clear":      "go1.21",
+	"max":        "go1.21",
+	"min":        "go1.21",
+}
+
+// lookupUniverse finds name in the universe scope of the Go version of s.
+func (s *Spec) lookupUniverse(name string) types.Object {
+	if v, ok := universeVersions[name]; ok && !allowVersion(s.goVersion, v) {
+		return nil
+	}
+	return types.Universe.Lookup(name)
+}
+
+// VersionDiff is the verdicts of a query about code under two Go versions.
+type VersionDiff struct {
+	OldVersion  string
+	NewVersion  string
+	OldVerdict  bool
+	NewVerdict  bool
+	OldCompiles bool // whether code compiles under OldVersion
+	NewCompiles bool // whether code compiles under NewVersion
+}
+
+// Changed reports whether the verdict changes between the two versions.
+func (d VersionDiff) Changed() bool {
+	return d.OldVerdict != d.NewVerdict
+}
+
+// DiffGoVersions answers query about code under the Go versions oldVersion and newVersion, see WithGoVersion.
+// For example, slices can be converted to array pointers since Go 1.17:
+//
+//	d, _ := DiffGoVersions(`var s []int`, "go1.16", "go1.17", func(s *Spec) bool {
+//		return s.Conversion("s", "*[4]int")
+//	})
+//	// d.Changed() is true
+//
+// query is called even if code fails to type-check under a version, see OldCompiles and NewCompiles.
+// An error is returned only if code can not be parsed.
+func DiffGoVersions(code, oldVersion, newVersion string, query func(s *Spec) bool, opts ...Option) (VersionDiff, error) {
+	d := VersionDiff{OldVersion: oldVersion, NewVersion: newVersion}
+	oldSpec, err := NewSpecE(code, append(opts, WithGoVersion(oldVersion))...)
+	if oldSpec == nil {
+		return d, err
+	}
+	newSpec, err := NewSpecE(code, append(opts, WithGoVersion(newVersion))...)
+	if newSpec == nil {
+		return d, err
+	}
+	d.OldVerdict, d.OldCompiles = query(oldSpec), oldSpec.Compiles()
+	d.NewVerdict, d.NewCompiles = query(newSpec), newSpec.Compiles()
+	return d, nil
+}
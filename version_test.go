@@ -0,0 +1,73 @@
+package gospec
+
+import "testing"
+
+// func WithGoVersion(version string) Option
+func TestWithGoVersion(t *testing.T) {
+	code := `var s []int`
+	if NewSpec(code, WithGoVersion("go1.16")).Conversion("s", "*[4]int") ||
+		!NewSpec(code, WithGoVersion("1.17")).Conversion("s", "*[4]int") ||
+		!NewSpec(code).Conversion("s", "*[4]int") {
+		t.Error(`test failed`)
+	}
+
+	s, err := NewSpecE(`func F[T any]() {}`, WithGoVersion("go1.17"))
+	if err == nil || s.Compiles() {
+		t.Error(`test failed`)
+	}
+	// the names added to the universe scope are not found before their Go versions
+	s = NewSpec(`var i int`, WithGoVersion("go1.16"))
+	if _, err := s.AssignmentE("i", "any"); err == nil {
+		t.Error(`test failed`)
+	}
+	if _, err := s.ConversionE("i", "any"); err == nil {
+		t.Error(`test failed`)
+	}
+	if s.GetTypeObject("comparable") != nil || s.IsInUniverse("min") || len(s.LookupAll("clear")) != 0 || s.GetTypeObject("len") == nil {
+		t.Error(`test failed`)
+	}
+	s = NewSpec(`var i int`, WithGoVersion("go1.18"))
+	if !s.Assignment("i", "any") || s.GetTypeObject("max") != nil || NewSpec(`var i int`).GetTypeObject("max") == nil {
+		t.Error(`test failed`)
+	}
+}
+
+// func DiffGoVersions(code, oldVersion, newVersion string, query func(s *Spec) bool) (VersionDiff, error)
+func TestDiffGoVersions(t *testing.T) {
+	type Info struct {
+		code       string
+		oldVersion string
+		newVersion string
+		query      func(s *Spec) bool
+		changed    bool
+	}
+	compiles := func(s *Spec) bool { return s.Compiles() }
+	infos := []Info{
+		// slice to array pointer conversion
+		{`var s []int`, "go1.16", "go1.17", func(s *Spec) bool { return s.Conversion("s", "*[4]int") }, true},
+		// slice to array conversion
+		{`var s []int`, "go1.19", "go1.20", func(s *Spec) bool { return s.Conversion("s", "[4]int") }, true},
+		{`var s []int`, "go1.17", "go1.19", func(s *Spec) bool { return s.Conversion("s", "[4]int") }, false},
+		// generics
+		{`func F[T any]() {}`, "go1.17", "go1.18", compiles, true},
+		// range over int
+		{`func f() { for i := range 10 { _ = i } }`, "go1.21", "go1.22", compiles, true},
+		// range over func
+		{`func f(seq func(func(int) bool)) { for i := range seq { _ = i } }`, "go1.22", "go1.23", compiles, true},
+		{`var a, b int`, "go1.14", "go1.23", func(s *Spec) bool { return s.Assignment("a", "int") }, false},
+	}
+	for _, v := range infos {
+		d, err := DiffGoVersions(v.code, v.oldVersion, v.newVersion, v.query)
+		if err != nil || d.Changed() != v.changed || d.OldVersion != v.oldVersion || d.NewVersion != v.newVersion {
+			t.Errorf("diff %s between %s and %s got %+v, %v", v.code, v.oldVersion, v.newVersion, d, err)
+		}
+	}
+
+	d, _ := DiffGoVersions(`func F[T any]() {}`, "go1.17", "go1.18", compiles)
+	if d.OldCompiles || !d.NewCompiles || d.OldVerdict || !d.NewVerdict {
+		t.Error(`test failed`)
+	}
+	if _, err := DiffGoVersions(`var a = `, "go1.17", "go1.18", compiles); err == nil {
+		t.Error(`test failed`)
+	}
+}